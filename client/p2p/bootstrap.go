@@ -0,0 +1,98 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/c3systems/go-substrate/client/p2p/defaults"
+	"github.com/c3systems/go-substrate/logger"
+
+	host "github.com/libp2p/go-libp2p-host"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// bootstrapBackoffStart is the initial delay between failed connection
+// attempts to a single bootstrap node; it doubles on every failure up to
+// bootstrapBackoffMax.
+const bootstrapBackoffStart = time.Second
+
+// bootstrapBackoffMax caps the per-node retry backoff.
+const bootstrapBackoffMax = time.Minute
+
+// connectBootstrapNodes dials every address in nodes, retrying each with
+// exponential backoff until it succeeds or ctx is done. It returns a
+// channel that is closed once at least minPeers of them have connected, so
+// callers (Start) can hold off running the sync loop against an empty
+// peerstore.
+func connectBootstrapNodes(ctx context.Context, h host.Host, nodes []ma.Multiaddr, minPeers int) <-chan struct{} {
+	ready := make(chan struct{})
+	if len(nodes) == 0 {
+		close(ready)
+		return ready
+	}
+
+	var (
+		mu        sync.Mutex
+		connected int
+		closed    bool
+	)
+
+	onConnected := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		connected++
+		if !closed && connected >= minPeers {
+			closed = true
+			close(ready)
+		}
+	}
+
+	for _, addr := range nodes {
+		go dialBootstrapNode(ctx, h, addr, onConnected)
+	}
+
+	return ready
+}
+
+// dialBootstrapNode retries connecting to addr until it succeeds or ctx is
+// canceled. On success it invokes onConnected exactly once; on cancellation
+// or an unparseable addr it returns without calling it.
+func dialBootstrapNode(ctx context.Context, h host.Host, addr ma.Multiaddr, onConnected func()) {
+	pinfo, err := peerstore.InfoFromP2pAddr(addr)
+	if err != nil {
+		logger.Errorf("[p2p] bootstrap: err parsing bootstrap addr %v\n%v", addr, err)
+		return
+	}
+
+	backoff := bootstrapBackoffStart
+	for {
+		if err := h.Connect(ctx, *pinfo); err == nil {
+			logger.Infof("[p2p] bootstrap: connected to %s", pinfo.ID.Pretty())
+			onConnected()
+			return
+		} else {
+			logger.Warnf("[p2p] bootstrap: err connecting to %s, retrying in %s\n%v", pinfo.ID.Pretty(), backoff, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+			backoff *= 2
+			if backoff > bootstrapBackoffMax {
+				backoff = bootstrapBackoffMax
+			}
+		}
+	}
+}
+
+// minBootstrapPeers is how many BootstrapNodes must connect before Start
+// proceeds to run the sync loop.
+func minBootstrapPeers() int {
+	if defaults.Defaults.MinBootstrapPeers > 0 {
+		return defaults.Defaults.MinBootstrapPeers
+	}
+	return 1
+}
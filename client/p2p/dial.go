@@ -0,0 +1,490 @@
+package p2p
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/c3systems/go-substrate/client/p2p/defaults"
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/logger"
+
+	manet "github.com/multiformats/go-multiaddr-net"
+)
+
+// errRecentlyDialed is returned when a peer is still inside its dial
+// cooldown window.
+var errRecentlyDialed = errors.New("p2p: already dialed recently")
+
+// errAlreadyConnected is returned when a peer already has a live
+// connection.
+var errAlreadyConnected = errors.New("p2p: already connected")
+
+// errSelf is returned when a task would dial our own peer ID.
+var errSelf = errors.New("p2p: is self")
+
+// errNotWhitelisted is returned when a peer's address falls outside the
+// configured NetRestrict whitelist.
+var errNotWhitelisted = errors.New("p2p: address not whitelisted")
+
+// dialHistoryExpiration bounds how far back taskDone remembers a failed
+// dial before it stops contributing to the cooldown.
+const dialHistoryExpiration = 30 * time.Minute
+
+// maxDialCooldown caps the exponentially-increasing backoff applied to a
+// peer that repeatedly fails to dial.
+const maxDialCooldown = 10 * time.Minute
+
+// defaultDialRatio is the divisor of maxDynPeers that sets how many slots
+// the scheduler will fill with outbound dials (maxDynPeers/defaultDialRatio,
+// i.e. one in three); the rest is left open for inbound connections so
+// the node doesn't end up dialing every slot itself. Mirrors go-ethereum's
+// p2p.Server.DialRatio default.
+const defaultDialRatio = 3
+
+// dialTask is one unit of scheduled dial work. Implementations dial a
+// single peer or run a discovery lookup, returning the error the scheduler
+// should record against that peer's history.
+type dialTask interface {
+	Do(p *P2P) error
+}
+
+// dynDialTask dials a peer discovered organically (via MDNS/DHT or an
+// inbound connection), subject to the dialstate's dynamic peer cap.
+type dynDialTask struct {
+	peer clienttypes.InterfacePeer
+}
+
+// Do implements dialTask.
+func (t *dynDialTask) Do(p *P2P) error {
+	return p.dialPeer(t.peer)
+}
+
+// staticDialTask dials a peer explicitly configured as static, regardless
+// of the dynamic peer cap.
+type staticDialTask struct {
+	peer clienttypes.InterfacePeer
+}
+
+// Do implements dialTask.
+func (t *staticDialTask) Do(p *P2P) error {
+	return p.dialPeer(t.peer)
+}
+
+// trustedDialTask dials a peer explicitly configured as trusted. Like
+// static peers these bypass the dynamic peer cap and NetRestrict, but they
+// are tracked separately so AddTrustedPeer actually results in dials
+// instead of only affecting checkDial's whitelist bypass.
+type trustedDialTask struct {
+	peer clienttypes.InterfacePeer
+}
+
+// Do implements dialTask.
+func (t *trustedDialTask) Do(p *P2P) error {
+	return p.dialPeer(t.peer)
+}
+
+// discoverTask asks the DHT/discovery service for fresh peers when the
+// known peer set is running low.
+type discoverTask struct{}
+
+// Do implements dialTask.
+func (t *discoverTask) Do(p *P2P) error {
+	if p.state == nil || p.state.Peers == nil {
+		return errors.New("p2p: nil state or peers service")
+	}
+
+	known, err := p.state.Peers.KnownPeers()
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("[p2p] discoverTask found %d known peers", len(known))
+	return nil
+}
+
+// dialHistoryEntry tracks a peer's dial failure streak so the scheduler can
+// apply an exponentially-increasing cooldown before retrying it.
+type dialHistoryEntry struct {
+	lastAttempt time.Time
+	fails       int
+}
+
+// cooldown returns how long must pass after lastAttempt before this peer
+// may be redialed.
+func (e *dialHistoryEntry) cooldown() time.Duration {
+	d := time.Duration(defaults.Defaults.DialBackoff)
+	for i := 0; i < e.fails && d < maxDialCooldown; i++ {
+		d *= 2
+	}
+	if d > maxDialCooldown {
+		return maxDialCooldown
+	}
+	return d
+}
+
+// DialMetrics is a point-in-time snapshot of the dial scheduler's load,
+// surfaced so operators can see why peering is (or isn't) progressing.
+type DialMetrics struct {
+	// Running is the number of dials currently in flight.
+	Running int
+	// Pending is the number of known peers sitting out a cooldown
+	// before they're eligible to be redialed.
+	Pending int
+	// Failed is the cumulative count of dial attempts that ended in
+	// error since the scheduler started.
+	Failed int
+	// Inbound and Outbound are the live connection counts recorded via
+	// recordConnection, used to track progress toward dialRatio.
+	Inbound  int
+	Outbound int
+}
+
+// dialstate is the dial scheduler: at each tick it is asked for up to
+// MaxPendingPeers new tasks given the currently running count and the set
+// of known peers, modeled on go-ethereum's p2p/dial.go. Its fields are read
+// and written from the dialPeers loop goroutine, the libp2p
+// connection-notifier goroutine (recordConnection), and external callers
+// (AddPeer, AddTrustedPeer, SetNetRestrict, DialMetrics), so mu guards all
+// of them.
+type dialstate struct {
+	mu sync.Mutex
+
+	maxDynPeers int
+	dialRatio   int
+
+	selfID string
+
+	netRestrict []*net.IPNet
+
+	static  map[string]clienttypes.InterfacePeer
+	trusted map[string]clienttypes.InterfacePeer
+
+	hist map[string]*dialHistoryEntry
+
+	dialing map[string]bool
+
+	failed            int
+	inbound, outbound int
+}
+
+// newDialState constructs a dialstate allowing up to maxDynPeers
+// concurrently-dialed dynamic (non-static) peers.
+func newDialState(maxDynPeers int) *dialstate {
+	return &dialstate{
+		maxDynPeers: maxDynPeers,
+		dialRatio:   defaultDialRatio,
+		static:      make(map[string]clienttypes.InterfacePeer),
+		trusted:     make(map[string]clienttypes.InterfacePeer),
+		hist:        make(map[string]*dialHistoryEntry),
+		dialing:     make(map[string]bool),
+	}
+}
+
+// setNetRestrict configures the CIDR whitelist; when non-empty, peers whose
+// address does not fall within one of these networks are never dialed.
+func (s *dialstate) setNetRestrict(nets []*net.IPNet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.netRestrict = nets
+}
+
+// setSelf records our own peer ID so checkDial can reject a self-dial
+// (e.g. our own advertised address coming back via discovery).
+func (s *dialstate) setSelf(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selfID = id
+}
+
+// recordConnection updates the live inbound/outbound connection counts
+// that targetOutboundSlots and Metrics report. Call it once per
+// connection established, in either direction.
+func (s *dialstate) recordConnection(outbound bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordConnectionLocked(outbound)
+}
+
+// recordConnectionLocked is recordConnection's body, for callers (taskDone)
+// that already hold s.mu.
+func (s *dialstate) recordConnectionLocked(outbound bool) {
+	if outbound {
+		s.outbound++
+		return
+	}
+	s.inbound++
+}
+
+// targetOutboundSlots returns how many dynamic outbound dials the
+// scheduler should have running or established at once, reserving the
+// rest of maxDynPeers for inbound connections so the peer set trends
+// toward dialRatio's outbound:total mix instead of the node dialing out
+// every slot itself. Callers must hold s.mu.
+func (s *dialstate) targetOutboundSlots() int {
+	if s.dialRatio <= 0 {
+		return s.maxDynPeers
+	}
+	target := s.maxDynPeers / s.dialRatio
+	if target < 1 {
+		target = 1
+	}
+	return target
+}
+
+// Metrics returns a snapshot of the scheduler's current dial load as of
+// now.
+func (s *dialstate) Metrics(now time.Time) DialMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := 0
+	for _, e := range s.hist {
+		if now.Before(e.lastAttempt.Add(e.cooldown())) {
+			pending++
+		}
+	}
+
+	return DialMetrics{
+		Running:  len(s.dialing),
+		Pending:  pending,
+		Failed:   s.failed,
+		Inbound:  s.inbound,
+		Outbound: s.outbound,
+	}
+}
+
+// addStatic registers peer as a static peer, always redialed regardless of
+// the dynamic peer cap.
+func (s *dialstate) addStatic(peer clienttypes.InterfacePeer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.static[peer.GetID()] = peer
+}
+
+// removeStatic unregisters a static peer.
+func (s *dialstate) removeStatic(peer clienttypes.InterfacePeer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.static, peer.GetID())
+}
+
+// addTrusted registers peer as trusted: it bypasses NetRestrict and peer
+// limits.
+func (s *dialstate) addTrusted(peer clienttypes.InterfacePeer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trusted[peer.GetID()] = peer
+}
+
+// checkDial reports whether peer may be dialed right now, given its
+// history, whitelist status, and whether it is already connected or being
+// dialed.
+func (s *dialstate) checkDial(peer clienttypes.InterfacePeer, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkDialLocked(peer, now)
+}
+
+// checkDialLocked is checkDial's body, for callers (newTasks) that already
+// hold s.mu.
+func (s *dialstate) checkDialLocked(peer clienttypes.InterfacePeer, now time.Time) error {
+	id := peer.GetID()
+
+	if s.selfID != "" && id == s.selfID {
+		return errSelf
+	}
+
+	if s.dialing[id] {
+		return errRecentlyDialed
+	}
+
+	if _, trusted := s.trusted[id]; !trusted {
+		if !s.whitelisted(peer) {
+			return errNotWhitelisted
+		}
+	}
+
+	if e, ok := s.hist[id]; ok && now.Before(e.lastAttempt.Add(e.cooldown())) {
+		return errRecentlyDialed
+	}
+
+	active, err := peer.IsActive()
+	if err != nil {
+		return err
+	}
+	if active {
+		return errAlreadyConnected
+	}
+
+	return nil
+}
+
+// whitelisted reports whether peer's address is allowed by NetRestrict; an
+// empty NetRestrict allows everything. Callers must hold s.mu.
+func (s *dialstate) whitelisted(peer clienttypes.InterfacePeer) bool {
+	if len(s.netRestrict) == 0 {
+		return true
+	}
+
+	for _, addr := range peer.Cfg().Peer.Addrs {
+		ip, err := manet.ToIP(addr)
+		if err != nil {
+			continue
+		}
+		for _, n := range s.netRestrict {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newTasks returns up to MaxPendingPeers dialTasks: trusted peers first,
+// then static peers (both uncapped), then dynamic peers up to
+// targetOutboundSlots running dials, plus a discoverTask when the known
+// peer set is thin.
+func (s *dialstate) newTasks(nRunning int, peers []clienttypes.InterfacePeer, now time.Time) []dialTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tasks []dialTask
+
+	for id, peer := range s.trusted {
+		if len(tasks) >= defaults.Defaults.MaxPendingPeers {
+			return tasks
+		}
+		if err := s.checkDialLocked(peer, now); err != nil {
+			continue
+		}
+		s.dialing[id] = true
+		tasks = append(tasks, &trustedDialTask{peer: peer})
+	}
+
+	for id, peer := range s.static {
+		if len(tasks) >= defaults.Defaults.MaxPendingPeers {
+			return tasks
+		}
+		if _, isTrusted := s.trusted[id]; isTrusted {
+			continue
+		}
+		if err := s.checkDialLocked(peer, now); err != nil {
+			continue
+		}
+		s.dialing[id] = true
+		tasks = append(tasks, &staticDialTask{peer: peer})
+	}
+
+	dynSlots := s.targetOutboundSlots() - nRunning - s.outbound
+	for _, peer := range peers {
+		if dynSlots <= 0 || len(tasks) >= defaults.Defaults.MaxPendingPeers {
+			break
+		}
+		id := peer.GetID()
+		if _, isStatic := s.static[id]; isStatic {
+			continue
+		}
+		if _, isTrusted := s.trusted[id]; isTrusted {
+			continue
+		}
+		if err := s.checkDialLocked(peer, now); err != nil {
+			continue
+		}
+		s.dialing[id] = true
+		tasks = append(tasks, &dynDialTask{peer: peer})
+		dynSlots--
+	}
+
+	if len(peers) == 0 && len(tasks) < defaults.Defaults.MaxPendingPeers {
+		tasks = append(tasks, &discoverTask{})
+	}
+
+	return tasks
+}
+
+// taskDone records the outcome of a completed dialTask: success clears the
+// peer's failure history and counts it as a new outbound connection;
+// failure bumps its cooldown and the cumulative failed-dial metric.
+func (s *dialstate) taskDone(t dialTask, now time.Time, dialErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var peer clienttypes.InterfacePeer
+	switch v := t.(type) {
+	case *dynDialTask:
+		peer = v.peer
+	case *staticDialTask:
+		peer = v.peer
+	case *trustedDialTask:
+		peer = v.peer
+	default:
+		return
+	}
+
+	id := peer.GetID()
+	delete(s.dialing, id)
+
+	if dialErr == nil {
+		delete(s.hist, id)
+		s.recordConnectionLocked(true)
+		return
+	}
+
+	s.failed++
+
+	e, ok := s.hist[id]
+	if !ok || now.Sub(e.lastAttempt) > dialHistoryExpiration {
+		e = &dialHistoryEntry{}
+		s.hist[id] = e
+	}
+	e.lastAttempt = now
+	e.fails++
+}
+
+// AddPeer registers peer as a static peer the scheduler will keep
+// redialing until it is removed.
+func (p *P2P) AddPeer(peer clienttypes.InterfacePeer) {
+	if p.dialer == nil || peer == nil {
+		return
+	}
+	p.dialer.addStatic(peer)
+}
+
+// RemovePeer unregisters a previously-added static peer.
+func (p *P2P) RemovePeer(peer clienttypes.InterfacePeer) {
+	if p.dialer == nil || peer == nil {
+		return
+	}
+	p.dialer.removeStatic(peer)
+}
+
+// AddTrustedPeer registers peer as trusted, bypassing NetRestrict and the
+// dynamic peer cap.
+func (p *P2P) AddTrustedPeer(peer clienttypes.InterfacePeer) {
+	if p.dialer == nil || peer == nil {
+		return
+	}
+	p.dialer.addTrusted(peer)
+}
+
+// SetNetRestrict configures the CIDR whitelist dials are restricted to; an
+// empty list disables restriction.
+func (p *P2P) SetNetRestrict(nets []*net.IPNet) {
+	if p.dialer == nil {
+		return
+	}
+	p.dialer.setNetRestrict(nets)
+}
+
+// DialMetrics returns a snapshot of the dial scheduler's current pending,
+// running, and failed dial counts, plus the live inbound/outbound
+// connection split.
+func (p *P2P) DialMetrics() DialMetrics {
+	if p.dialer == nil {
+		return DialMetrics{}
+	}
+	return p.dialer.Metrics(time.Now())
+}
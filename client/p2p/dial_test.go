@@ -0,0 +1,180 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/c3systems/go-substrate/client/p2p/peers"
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+
+	libpeer "github.com/libp2p/go-libp2p-peer"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func testPeer(t *testing.T, id string, addrs ...string) clienttypes.InterfacePeer {
+	t.Helper()
+
+	info := peerstore.PeerInfo{ID: libpeer.ID(id)}
+	for _, a := range addrs {
+		maddr, err := ma.NewMultiaddr(a)
+		if err != nil {
+			t.Fatalf("NewMultiaddr(%s): %v", a, err)
+		}
+		info.Addrs = append(info.Addrs, maddr)
+	}
+
+	return peers.NewPeer(info)
+}
+
+func TestCheckDialRejectsSelf(t *testing.T) {
+	s := newDialState(8)
+	self := testPeer(t, "self")
+	s.setSelf(self.GetID())
+
+	if err := s.checkDial(self, time.Now()); err != errSelf {
+		t.Fatalf("checkDial(self) = %v, want %v", err, errSelf)
+	}
+}
+
+func TestNewTasksDialsTrustedPeerEvenWhenNotStatic(t *testing.T) {
+	s := newDialState(8)
+	trusted := testPeer(t, "trusted-1")
+	s.addTrusted(trusted)
+
+	tasks := s.newTasks(0, nil, time.Now())
+
+	var gotTrusted bool
+	for _, task := range tasks {
+		if tt, ok := task.(*trustedDialTask); ok && tt.peer.GetID() == trusted.GetID() {
+			gotTrusted = true
+		}
+	}
+	if !gotTrusted {
+		t.Fatalf("newTasks did not schedule the trusted peer: %v", tasks)
+	}
+}
+
+func TestNewTasksSkipsTrustedPeerAlreadyDialing(t *testing.T) {
+	s := newDialState(8)
+	trusted := testPeer(t, "trusted-2")
+	s.addTrusted(trusted)
+
+	// With no known peers newTasks also schedules a discoverTask, so only
+	// the trusted dials are counted here.
+	first := s.newTasks(0, nil, time.Now())
+	var firstTrusted int
+	for _, task := range first {
+		if _, ok := task.(*trustedDialTask); ok {
+			firstTrusted++
+		}
+	}
+	if firstTrusted != 1 {
+		t.Fatalf("first newTasks = %d trusted tasks, want 1", firstTrusted)
+	}
+
+	second := s.newTasks(0, nil, time.Now())
+	for _, task := range second {
+		if _, ok := task.(*trustedDialTask); ok {
+			t.Fatalf("trusted peer dialed twice before taskDone: %v", second)
+		}
+	}
+}
+
+func TestNewTasksRespectsWhitelist(t *testing.T) {
+	s := newDialState(8)
+	s.setNetRestrict(parseCIDRs(t, "10.0.0.0/8"))
+
+	allowed := testPeer(t, "allowed", "/ip4/10.1.2.3/tcp/30000")
+	blocked := testPeer(t, "blocked", "/ip4/1.2.3.4/tcp/30000")
+
+	tasks := s.newTasks(0, []clienttypes.InterfacePeer{allowed, blocked}, time.Now())
+
+	var gotAllowed, gotBlocked bool
+	for _, task := range tasks {
+		dt, ok := task.(*dynDialTask)
+		if !ok {
+			continue
+		}
+		switch dt.peer.GetID() {
+		case allowed.GetID():
+			gotAllowed = true
+		case blocked.GetID():
+			gotBlocked = true
+		}
+	}
+	if !gotAllowed {
+		t.Fatal("whitelisted peer was not dialed")
+	}
+	if gotBlocked {
+		t.Fatal("non-whitelisted peer was dialed")
+	}
+}
+
+func TestNewTasksRespectsDialRatio(t *testing.T) {
+	// maxDynPeers=9, default dialRatio=3 -> at most 3 outbound dynamic
+	// dials may be scheduled/in-flight at once.
+	s := newDialState(9)
+
+	var candidates []clienttypes.InterfacePeer
+	for i := 0; i < 9; i++ {
+		candidates = append(candidates, testPeer(t, "dyn-"+string(rune('a'+i))))
+	}
+
+	tasks := s.newTasks(0, candidates, time.Now())
+
+	var dynCount int
+	for _, task := range tasks {
+		if _, ok := task.(*dynDialTask); ok {
+			dynCount++
+		}
+	}
+	if dynCount != 3 {
+		t.Fatalf("scheduled %d dynamic dials, want 3 (targetOutboundSlots)", dynCount)
+	}
+}
+
+func TestTaskDoneTracksCooldownAndMetrics(t *testing.T) {
+	s := newDialState(8)
+	peer := testPeer(t, "flaky")
+
+	task := &dynDialTask{peer: peer}
+	s.dialing[peer.GetID()] = true
+
+	now := time.Now()
+	s.taskDone(task, now, errAlreadyConnected)
+
+	m := s.Metrics(now)
+	if m.Failed != 1 {
+		t.Fatalf("Failed = %d, want 1", m.Failed)
+	}
+	if err := s.checkDial(peer, now); err != errRecentlyDialed {
+		t.Fatalf("checkDial right after a failure = %v, want %v", err, errRecentlyDialed)
+	}
+
+	// after the cooldown window, the peer is eligible again.
+	later := now.Add(maxDialCooldown + time.Second)
+	if err := s.checkDial(peer, later); err == errRecentlyDialed {
+		t.Fatalf("checkDial after cooldown still = %v", err)
+	}
+
+	s.dialing[peer.GetID()] = true
+	s.taskDone(task, later, nil)
+	if s.Metrics(later).Outbound != 1 {
+		t.Fatalf("Outbound = %d, want 1 after a successful dial", s.Metrics(later).Outbound)
+	}
+}
+
+func parseCIDRs(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+	var out []*net.IPNet
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			t.Fatalf("ParseCIDR(%s): %v", c, err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package p2p
+
+import (
+	"fmt"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+)
+
+// errorCode classifies a peerError so callers can react to specific
+// protocol violations (e.g. disconnecting with a matching DiscReason)
+// instead of string-matching log output.
+type errorCode int
+
+// Peer error codes.
+const (
+	errInvalidMsgCode errorCode = iota
+	errInvalidMsg
+	errProtocolReturned
+	errPingTimeout
+	errReadTimeout
+)
+
+var errorCodeStrings = map[errorCode]string{
+	errInvalidMsgCode:   "invalid message code",
+	errInvalidMsg:       "invalid message",
+	errProtocolReturned: "subprotocol returned",
+	errPingTimeout:      "ping timed out",
+	errReadTimeout:      "read timed out",
+}
+
+// discReasons maps each errorCode to the DiscReason a peer should be
+// disconnected with, so a peerError always carries enough information to
+// both log what went wrong and tell the remote end why.
+var discReasons = map[errorCode]clienttypes.DiscReason{
+	errInvalidMsgCode:   clienttypes.DiscProtocolError,
+	errInvalidMsg:       clienttypes.DiscProtocolError,
+	errProtocolReturned: clienttypes.DiscSubprotocolError,
+	errPingTimeout:      clienttypes.DiscNetworkError,
+	errReadTimeout:      clienttypes.DiscNetworkError,
+}
+
+// peerError is a structured error describing why a peer connection is
+// being abandoned, carrying an errorCode plus a free-form message.
+type peerError struct {
+	code    errorCode
+	message string
+}
+
+// newPeerError builds a peerError, formatting message/args with fmt.Sprintf
+// when args are given.
+func newPeerError(code errorCode, message string, args ...interface{}) *peerError {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return &peerError{code: code, message: message}
+}
+
+// discReason reports the DiscReason a peer should be disconnected with for
+// this error, falling back to DiscSubprotocolError for any code that isn't
+// explicitly mapped.
+func (e *peerError) discReason() clienttypes.DiscReason {
+	if reason, ok := discReasons[e.code]; ok {
+		return reason
+	}
+	return clienttypes.DiscSubprotocolError
+}
+
+// Error implements error.
+func (e *peerError) Error() string {
+	name, ok := errorCodeStrings[e.code]
+	if !ok {
+		return e.message
+	}
+	if e.message == "" {
+		return name
+	}
+	return name + ": " + e.message
+}
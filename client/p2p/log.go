@@ -0,0 +1,57 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/logger"
+)
+
+// peerLog is a context-scoped structured logger carrying a peer's
+// identifying key/value pairs (peer, addr, proto) so operator logs
+// correlate across that peer's whole lifetime instead of being free-form
+// Errorf strings.
+type peerLog struct {
+	ctx    context.Context
+	fields string
+}
+
+// newPeerLog builds a peerLog for pr, optionally naming the negotiated
+// subprotocol (proto/version) it's currently scoped to.
+func newPeerLog(ctx context.Context, pr clienttypes.InterfacePeer, protoName string, protoVersion uint) *peerLog {
+	fields := fmt.Sprintf("peer=%s addr=%s", pr.GetID(), pr.Cfg().Peer.Addrs)
+	if protoName != "" {
+		fields = fmt.Sprintf("%s proto=%s/%d", fields, protoName, protoVersion)
+	}
+
+	return &peerLog{ctx: ctx, fields: fields}
+}
+
+// taggedFields returns this peer's key/value fields, plus ctxErr=<err> when
+// l.ctx has already been canceled or timed out - so a burst of peer errors
+// right as the node is shutting down reads as "expected, we're quitting"
+// rather than looking like an unrelated peer failure.
+func (l *peerLog) taggedFields() string {
+	if l.ctx != nil {
+		if err := l.ctx.Err(); err != nil {
+			return l.fields + " ctxErr=" + err.Error()
+		}
+	}
+	return l.fields
+}
+
+// Errorf logs at error level with this peer's fields prefixed.
+func (l *peerLog) Errorf(format string, args ...interface{}) {
+	logger.Errorf("[p2p] "+l.taggedFields()+" "+format, args...)
+}
+
+// Warnf logs at warn level with this peer's fields prefixed.
+func (l *peerLog) Warnf(format string, args ...interface{}) {
+	logger.Warnf("[p2p] "+l.taggedFields()+" "+format, args...)
+}
+
+// Infof logs at info level with this peer's fields prefixed.
+func (l *peerLog) Infof(format string, args ...interface{}) {
+	logger.Infof("[p2p] "+l.taggedFields()+" "+format, args...)
+}
@@ -7,11 +7,14 @@ import (
 	"crypto/rand"
 	"errors"
 	"io"
+	stdnet "net"
 	"time"
 
 	"github.com/c3systems/go-substrate/client/p2p/defaults"
 	"github.com/c3systems/go-substrate/client/p2p/handler"
+	handlertypes "github.com/c3systems/go-substrate/client/p2p/handler/types"
 	"github.com/c3systems/go-substrate/client/p2p/peers"
+	subprotocol "github.com/c3systems/go-substrate/client/p2p/protocol"
 	"github.com/c3systems/go-substrate/client/p2p/sync"
 	p2ptypes "github.com/c3systems/go-substrate/client/p2p/types"
 	clienttypes "github.com/c3systems/go-substrate/client/types"
@@ -67,12 +70,12 @@ func New(ctx context.Context, cancel context.CancelFunc, ch chan interface{}, cf
 			SyncState: &clienttypes.SyncState{},
 			Peers:     prs,
 		},
-		cfg:       cfg,
-		ctx:       ctx,
-		ch:        ch,
-		sync:      snc,
-		cancel:    cancel,
-		dialQueue: make(map[string]*clienttypes.QueuedPeer),
+		cfg:    cfg,
+		ctx:    ctx,
+		ch:     ch,
+		sync:   snc,
+		cancel: cancel,
+		dialer: newDialState(defaults.Defaults.MaxDynPeers),
 	}
 
 	return p, nil
@@ -196,32 +199,25 @@ func (p *P2P) Start() error {
 	//return nil, fmt.Errorf("err building new pubsub service\n%v", err)
 	//}
 
-	// TODO ...
-	//if cfg.Peer != "" {
-	//addr, err := ipfsaddr.ParseString(cfg.Peer)
-	//if err != nil {
-	//return nil, fmt.Errorf("err parsing node uri flag: %s\n%v", cfg.URI, err)
-	//}
-
-	//pinfo, err := peerstore.InfoFromP2pAddr(addr.Multiaddr())
-	//if err != nil {
-	//return nil, fmt.Errorf("err getting info from peerstore\n%v", err)
-	//}
-
-	//log.Println("[node] FULL", addr.String())
-	//log.Println("[node] PIN INFO", pinfo)
-
-	//if err := newNode.Connect(ctx, *pinfo); err != nil {
-	//return nil, fmt.Errorf("[node] bootstrapping a peer failed\n%v", err)
-	//}
-
-	//newNode.Peerstore().AddAddrs(pinfo.ID, pinfo.Addrs, peerstore.PermanentAddrTTL)
-	//}
 	nb := &net.NotifyBundle{
 		ConnectedF: p.onConn,
 	}
 	newNode.Network().Notify(nb)
 	p.state.Host = newNode
+	p.dialer.setSelf(newNode.ID().Pretty())
+
+	// 8. connect to configured bootstrap nodes and wait for at least
+	// MinBootstrapPeers of them before letting the sync loop start
+	// against what would otherwise be an empty peerstore.
+	if len(p.cfg.P2P.BootstrapNodes) > 0 {
+		ready := connectBootstrapNodes(p.cfg.P2P.Context, newNode, p.cfg.P2P.BootstrapNodes, minBootstrapPeers())
+		select {
+		case <-ready:
+			logger.Infof("[p2p] connected to %d bootstrap peer(s)", minBootstrapPeers())
+		case <-p.cfg.P2P.Context.Done():
+			return p.cfg.P2P.Context.Err()
+		}
+	}
 
 	// TODO: start pingPeer, handleProtocol, etc!
 	return nil
@@ -259,6 +255,13 @@ func (p *P2P) Cfg() clienttypes.ConfigClient {
 func (p *P2P) onConn(network net.Network, conn net.Conn) {
 	logger.Infof("[p2p] peer did connect\nid %v peerAddr %v", conn.RemotePeer().Pretty(), conn.RemoteMultiaddr())
 
+	// Outbound connections are already counted by the dial scheduler
+	// when its dial task succeeds; only inbound connections need to be
+	// recorded here for the dialRatio target to mean anything.
+	if conn.Stat().Direction == net.DirInbound {
+		p.dialer.recordConnection(false)
+	}
+
 	p.addAddr(conn)
 }
 
@@ -341,10 +344,12 @@ func (p *P2P) protocolHandler(stream net.Stream) {
 		return
 	}
 
+	plog := newPeerLog(context.Background(), pr.Peer, "dot", 1)
+
 	// TODO: check if is connected?
 	ok, err := pr.Peer.IsWritable()
 	if err != nil {
-		logger.Errorf("[p2p] err checking if peer is writable\n%v", err)
+		plog.Errorf("err checking if peer is writable\n%v", err)
 		return
 	}
 	if !ok {
@@ -352,9 +357,82 @@ func (p *P2P) protocolHandler(stream net.Stream) {
 		go p.dialPeers(pr.Peer)
 	}
 
+	pr.Peer.SetStream(stream)
+
+	if err := subprotocol.Serve(pr.Peer, stream, []subprotocol.Protocol{p.dotProtocol()}); err != nil {
+		plog.Errorf("err serving dot subprotocol\n%v", err)
+		pe, ok := err.(*peerError)
+		if !ok {
+			pe = newPeerError(errProtocolReturned, "%v", err)
+		}
+		pr.Peer.Disconnect(pe.discReason())
+	}
+
 	return
 }
 
+// dotProtocolLength reserves message codes for the "dot" subprotocol's
+// status and block sync messages; sibling subprotocols (light client,
+// gossip, telemetry, ...) register with their own Protocol and get the
+// next contiguous block of codes without touching this file.
+const dotProtocolLength = 16
+
+// dotProtocol wraps the existing block request/response handling as a
+// subprotocol.Protocol so it can be negotiated and multiplexed alongside
+// future subprotocols on the same stream. Only BlockRequest and
+// BlockResponse codes are decoded; any other code, status messages
+// included, ends the run with an errInvalidMsgCode peer error.
+func (p *P2P) dotProtocol() subprotocol.Protocol {
+	return subprotocol.Protocol{
+		Name:    "dot",
+		Version: 1,
+		Length:  dotProtocolLength,
+		Run:     p.runDotProtocol,
+	}
+}
+
+// runDotProtocol decodes each incoming frame into the clienttypes message
+// its code identifies and dispatches it through the existing
+// handler.FromEnum lookup, the same path handlePeerMessage already used.
+func (p *P2P) runDotProtocol(peer clienttypes.InterfacePeer, rw subprotocol.MsgReadWriter) error {
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+
+		var m clienttypes.Message
+		switch handlertypes.FuncEnum(msg.Code) {
+		case handlertypes.BlockResponse:
+			m = &clienttypes.BlockResponse{}
+		case handlertypes.BlockRequest:
+			m = &clienttypes.BlockRequest{}
+		default:
+			if err := msg.Discard(); err != nil {
+				return err
+			}
+			return newPeerError(errInvalidMsgCode, "peer %s: unknown code %d", peer.GetID(), msg.Code)
+		}
+
+		if err := msg.Decode(m); err != nil {
+			return newPeerError(errInvalidMsg, "peer %s: %v", peer.GetID(), err)
+		}
+		if err := p.handlePeerMessage(&clienttypes.OnMessage{Peer: peer, Message: m}); err != nil {
+			logger.Errorf("[p2p] err handling dot subprotocol msg from peer %s\n%v", peer.GetID(), err)
+		}
+	}
+}
+
+// taskResult carries a completed dialTask back to the scheduler loop so
+// taskDone can update the peer's failure history.
+type taskResult struct {
+	task dialTask
+	err  error
+}
+
+// dialPeers runs the dial scheduler for the lifetime of the p2p service.
+// pr, when non-nil, is an inbound peer we are not yet writable to and
+// should attempt to dial immediately in addition to the regular schedule.
 func (p *P2P) dialPeers(pr clienttypes.InterfacePeer) {
 	if !p.IsStarted() {
 		logger.Error("p2p host not started")
@@ -362,48 +440,48 @@ func (p *P2P) dialPeers(pr clienttypes.InterfacePeer) {
 	}
 
 	if pr != nil {
-		if _, ok := p.dialQueue[pr.GetID()]; !ok {
-			p.dialQueue[pr.GetID()] = &clienttypes.QueuedPeer{
-				Peer:     pr,
-				NextDial: time.Now(),
+		go func() {
+			if err := p.dialPeer(pr); err != nil {
+				logger.Errorf("[p2p] err dialing peer id %s\n%v", pr.GetID(), err)
 			}
-		}
+		}()
 	}
 
-	var (
-		now time.Time
-		k   string
-	)
+	var nRunning int
+	results := make(chan taskResult, defaults.Defaults.MaxPendingPeers)
+
 	for {
 		select {
 		case <-p.ctx.Done():
-			{
-				logger.Info("[p2p] context canceled. Stopping dialPeers.")
-				return
-			}
+			logger.Info("[p2p] context canceled. Stopping dialPeers.")
+			return
+		case res := <-results:
+			nRunning--
+			p.dialer.taskDone(res.task, time.Now(), res.err)
 		case <-time.After(time.Duration(defaults.Defaults.DialInterval)):
-			{
-				now = time.Now()
-				var (
-					err    error
-					active bool
-				)
-				for k = range p.dialQueue {
-					if p.dialQueue[k] == nil || p.dialQueue[k].NextDial.After(now) || p.dialQueue[k].Peer == nil {
-						continue
-					}
-					active, err = p.dialQueue[k].Peer.IsActive()
-					if err != nil || active {
-						continue
-					}
+			knownPeers, err := p.state.Peers.KnownPeers()
+			if err != nil {
+				logger.Errorf("[p2p] err listing known peers for dial scheduler\n%v", err)
+				continue
+			}
 
-					p.dialQueue[k].NextDial = p.dialQueue[k].NextDial.Add(time.Duration(defaults.Defaults.DialBackoff))
-					if err = p.dialPeer(p.dialQueue[k].Peer); err != nil {
-						// TODO: nil check
-						logger.Errorf("[p2p] err dialing peer id %s\n%v", p.dialQueue[k].Peer.GetID(), err)
-					}
+			peers := make([]clienttypes.InterfacePeer, 0, len(knownPeers))
+			for _, kp := range knownPeers {
+				if kp != nil && kp.Peer != nil {
+					peers = append(peers, kp.Peer)
 				}
 			}
+
+			for _, task := range p.dialer.newTasks(nRunning, peers, time.Now()) {
+				nRunning++
+				go func(t dialTask) {
+					err := t.Do(p)
+					if err != nil {
+						logger.Errorf("[p2p] err running dial task\n%v", err)
+					}
+					results <- taskResult{task: t, err: err}
+				}(task)
+			}
 		}
 	}
 }
@@ -416,6 +494,8 @@ func (p *P2P) dialPeer(pr clienttypes.InterfacePeer) error {
 		return errors.New("p2p host not started")
 	}
 
+	plog := newPeerLog(context.Background(), pr, "", 0)
+
 	var (
 		conn net.Conn
 		err  error
@@ -423,9 +503,10 @@ func (p *P2P) dialPeer(pr clienttypes.InterfacePeer) error {
 	// note: check for nil?
 	conns := p.state.Host.Network().ConnsToPeer(pr.Cfg().Peer.ID)
 	if conns == nil || len(conns) == 0 {
-		logger.Infof("[p2p] dialing peer with id %s", pr.GetID())
+		plog.Infof("dialing peer")
 		conn, err = p.state.Host.Network().DialPeer(context.Background(), pr.Cfg().Peer.ID)
 		if err != nil {
+			plog.Errorf("err dialing peer\n%v", err)
 			return err
 		}
 	} else {
@@ -477,8 +558,17 @@ func (p *P2P) pingHandler(stream net.Stream) {
 	}
 }
 
+// maxPingFailures is how many consecutive failed pings pingPeer tolerates
+// before disconnecting the peer as unreachable.
+const maxPingFailures = 3
+
 func (p *P2P) pingPeer(pr clienttypes.InterfacePeer) error {
-	var err error
+	plog := newPeerLog(p.ctx, pr, "", 0)
+
+	var (
+		err   error
+		fails int
+	)
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -489,8 +579,16 @@ func (p *P2P) pingPeer(pr clienttypes.InterfacePeer) error {
 		case <-time.After(time.Duration(defaults.Defaults.PingInterval)):
 			{
 				if err = p.sendPingToPeer(pr); err != nil {
-					logger.Errorf("[p2p] err sending ping to peer with ID %v\n%v", pr.Cfg().Peer.ID, err)
+					fails++
+					plog.Errorf("err sending ping (%d/%d consecutive failures)\n%v", fails, maxPingFailures, err)
+
+					if fails >= maxPingFailures {
+						pr.Disconnect(clienttypes.DiscNetworkError)
+						return newPeerError(errPingTimeout, "peer %s: %v", pr.GetID(), err)
+					}
+					continue
 				}
+				fails = 0
 			}
 		}
 	}
@@ -538,15 +636,17 @@ func (p *P2P) sendPingToPeer(pr clienttypes.InterfacePeer) error {
 	}
 
 	var (
-		b2 []byte
+		b2 = make([]byte, 32)
 		c  byte
 		nb int
 	)
 	for {
 		c, err = r.ReadByte()
 		if err == nil {
-			b2[nb] = c
-			nb++
+			if nb < len(b2) {
+				b2[nb] = c
+				nb++
+			}
 			continue
 		}
 		if err == io.EOF {
@@ -556,6 +656,9 @@ func (p *P2P) sendPingToPeer(pr clienttypes.InterfacePeer) error {
 			break
 		}
 
+		if nerr, ok := err.(stdnet.Error); ok && nerr.Timeout() {
+			return newPeerError(errReadTimeout, "peer %s: %v", pr.GetID(), err)
+		}
 		return err
 	}
 
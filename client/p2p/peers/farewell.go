@@ -0,0 +1,50 @@
+package peers
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+)
+
+// FarewellCode is the out-of-band message code reserved for disconnect
+// notifications, sent ahead of closing a peer's stream. It sits outside
+// any subprotocol's code space, and is exported so the protocol package's
+// demuxer can recognize it and log the reason instead of dropping it as an
+// unknown frame.
+const FarewellCode uint64 = ^uint64(0)
+
+// errFarewellPayload is returned when a frame claiming FarewellCode
+// doesn't carry exactly the one reason byte writeFarewell sends.
+var errFarewellPayload = errors.New("peers: malformed farewell payload")
+
+// writeFarewell writes a single frame carrying reason to w, using the same
+// fixed-width LE framing as the protocol package's readFrame/writeFrame
+// (8-byte code, 4-byte size, payload).
+func writeFarewell(w io.Writer, reason clienttypes.DiscReason) error {
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint64(header, FarewellCode)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	sizeBuf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBuf, 1)
+	if _, err := w.Write(sizeBuf); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte{byte(reason)})
+	return err
+}
+
+// ReadFarewellReason decodes the single-byte payload of a FarewellCode
+// frame (as written by writeFarewell) back into the DiscReason the sender
+// disconnected with.
+func ReadFarewellReason(payload []byte) (clienttypes.DiscReason, error) {
+	if len(payload) != 1 {
+		return 0, errFarewellPayload
+	}
+	return clienttypes.DiscReason(payload[0]), nil
+}
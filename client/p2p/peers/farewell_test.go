@@ -0,0 +1,46 @@
+package peers
+
+import (
+	"bytes"
+	"testing"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+)
+
+func TestWriteReadFarewellRoundTrip(t *testing.T) {
+	for _, reason := range []clienttypes.DiscReason{
+		clienttypes.DiscRequested,
+		clienttypes.DiscProtocolError,
+		clienttypes.DiscSubprotocolError,
+	} {
+		buf := new(bytes.Buffer)
+		if err := writeFarewell(buf, reason); err != nil {
+			t.Fatalf("writeFarewell(%s): %v", reason, err)
+		}
+
+		// header (8-byte code, 4-byte size) matches the framing the
+		// protocol package's demuxer parses.
+		header := buf.Bytes()[:12]
+		payload := buf.Bytes()[12:]
+
+		if len(header) != 12 {
+			t.Fatalf("unexpected header length %d", len(header))
+		}
+
+		got, err := ReadFarewellReason(payload)
+		if err != nil {
+			t.Fatalf("ReadFarewellReason: %v", err)
+		}
+		if got != reason {
+			t.Fatalf("ReadFarewellReason round-trip = %s, want %s", got, reason)
+		}
+	}
+}
+
+func TestReadFarewellReasonRejectsBadPayload(t *testing.T) {
+	for _, payload := range [][]byte{nil, {}, {0x01, 0x02}} {
+		if _, err := ReadFarewellReason(payload); err == nil {
+			t.Fatalf("ReadFarewellReason(%x) = nil error, want one", payload)
+		}
+	}
+}
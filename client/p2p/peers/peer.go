@@ -0,0 +1,105 @@
+// Package peers tracks known and connected libp2p peers for the p2p
+// service: their identity, connection state, and farewell/disconnect
+// handling.
+package peers
+
+import (
+	"sync"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/logger"
+
+	libp2pnet "github.com/libp2p/go-libp2p-net"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+)
+
+// Ensure Peer implements InterfacePeer.
+var _ clienttypes.InterfacePeer = (*Peer)(nil)
+
+// Peer is the concrete InterfacePeer: one libp2p peer's identity plus its
+// current stream/connection.
+type Peer struct {
+	info peerstore.PeerInfo
+
+	mu       sync.Mutex
+	conn     libp2pnet.Conn
+	stream   libp2pnet.Stream
+	writable bool
+	active   bool
+}
+
+// NewPeer returns a Peer for info, with no connection established yet.
+func NewPeer(info peerstore.PeerInfo) *Peer {
+	return &Peer{info: info}
+}
+
+// GetID implements clienttypes.InterfacePeer.
+func (p *Peer) GetID() string {
+	return p.info.ID.Pretty()
+}
+
+// Cfg implements clienttypes.InterfacePeer.
+func (p *Peer) Cfg() clienttypes.PeerConfig {
+	return clienttypes.PeerConfig{Peer: p.info}
+}
+
+// IsWritable implements clienttypes.InterfacePeer.
+func (p *Peer) IsWritable() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.writable, nil
+}
+
+// IsActive implements clienttypes.InterfacePeer.
+func (p *Peer) IsActive() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active, nil
+}
+
+// AddConnection implements clienttypes.InterfacePeer, recording conn as
+// this peer's active connection.
+func (p *Peer) AddConnection(conn libp2pnet.Conn, outbound bool) (libp2pnet.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conn = conn
+	p.active = true
+	p.writable = true
+
+	return conn, nil
+}
+
+// SetStream implements clienttypes.InterfacePeer.
+func (p *Peer) SetStream(stream libp2pnet.Stream) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stream = stream
+}
+
+// Disconnect implements clienttypes.InterfacePeer: it writes a farewell
+// frame carrying reason on the peer's stream (best effort), then closes
+// the stream and underlying connection.
+func (p *Peer) Disconnect(reason clienttypes.DiscReason) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stream != nil {
+		if err := writeFarewell(p.stream, reason); err != nil {
+			logger.Warnf("[peers] err writing farewell to peer %s\n%v", p.info.ID.Pretty(), err)
+		}
+		if err := p.stream.Close(); err != nil {
+			logger.Warnf("[peers] err closing stream to peer %s\n%v", p.info.ID.Pretty(), err)
+		}
+		p.stream = nil
+	}
+	if p.conn != nil {
+		if err := p.conn.Close(); err != nil {
+			logger.Warnf("[peers] err closing connection to peer %s\n%v", p.info.ID.Pretty(), err)
+		}
+		p.conn = nil
+	}
+
+	p.writable = false
+	p.active = false
+}
@@ -0,0 +1,64 @@
+package peers
+
+import (
+	"errors"
+	"sync"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+)
+
+// Peers is the peer-store service held on clienttypes.State: every peer
+// the node has ever heard of, keyed by peer ID, registered lazily on
+// first Add.
+type Peers struct {
+	mu    sync.Mutex
+	peers map[string]*clienttypes.KnownPeer
+}
+
+// New returns an empty Peers service for cfg's node.
+func New(cfg *clienttypes.ConfigClient) (*Peers, error) {
+	if cfg == nil {
+		return nil, errors.New("peers: nil config")
+	}
+
+	return &Peers{peers: make(map[string]*clienttypes.KnownPeer)}, nil
+}
+
+// Add registers (or returns the already-registered) KnownPeer for info.
+func (s *Peers) Add(info peerstore.PeerInfo) (*clienttypes.KnownPeer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := info.ID.Pretty()
+	if kp, ok := s.peers[id]; ok {
+		return kp, nil
+	}
+
+	kp := &clienttypes.KnownPeer{Peer: NewPeer(info)}
+	s.peers[id] = kp
+
+	return kp, nil
+}
+
+// KnownPeers returns a snapshot of every peer this service has seen.
+func (s *Peers) KnownPeers() ([]*clienttypes.KnownPeer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*clienttypes.KnownPeer, 0, len(s.peers))
+	for _, kp := range s.peers {
+		out = append(out, kp)
+	}
+
+	return out, nil
+}
+
+// Count returns how many peers this service knows about.
+func (s *Peers) Count() (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return uint(len(s.peers)), nil
+}
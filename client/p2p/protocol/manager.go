@@ -0,0 +1,136 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/c3systems/go-substrate/client/p2p/peers"
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/logger"
+)
+
+// protoRW is the MsgReadWriter handed to a single negotiated protocol's Run
+// function. Writes are translated to wire frames (with the protocol's code
+// offset added back in) on the shared stream; reads are served from a
+// per-protocol channel fed by the demuxer goroutine.
+type protoRW struct {
+	proto  *offsetProtocol
+	stream io.Writer
+	wmu    *sync.Mutex
+
+	in chan Msg
+}
+
+// WriteMsg implements MsgWriter by adding the protocol's code offset and
+// writing a single wire frame to the shared stream.
+func (rw *protoRW) WriteMsg(msg Msg) error {
+	if msg.Code >= rw.proto.Length {
+		return fmt.Errorf("protocol: msg code %d out of range for %s (length %d)", msg.Code, rw.proto.Name, rw.proto.Length)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, msg.Payload); err != nil {
+		return err
+	}
+
+	rw.wmu.Lock()
+	defer rw.wmu.Unlock()
+	return writeFrame(rw.stream, rw.proto.offset+msg.Code, buf.Bytes())
+}
+
+// ReadMsg implements MsgReader, blocking on the channel the demuxer
+// delivers this protocol's frames to.
+func (rw *protoRW) ReadMsg() (Msg, error) {
+	msg, ok := <-rw.in
+	if !ok {
+		return Msg{}, io.EOF
+	}
+	return msg, nil
+}
+
+// Serve negotiates protos with the peer over stream, then demuxes incoming
+// frames to each matched protocol's Run goroutine until the stream closes
+// or every Run returns. It blocks until all Run goroutines have returned.
+func Serve(peer clienttypes.InterfacePeer, stream io.ReadWriter, protos []Protocol) error {
+	matched, err := Negotiate(stream, protos)
+	if err != nil {
+		return err
+	}
+
+	var wmu sync.Mutex
+	rws := make(map[uint64]*protoRW, len(matched))
+	var wg sync.WaitGroup
+
+	var runErrOnce sync.Once
+	var runErr error
+
+	for name, p := range matched {
+		rw := &protoRW{proto: p, stream: stream, wmu: &wmu, in: make(chan Msg, 16)}
+		for code := uint64(0); code < p.Length; code++ {
+			rws[p.offset+code] = rw
+		}
+
+		wg.Add(1)
+		go func(name string, p *offsetProtocol, rw *protoRW) {
+			defer wg.Done()
+			if err := p.Run(peer, rw); err != nil {
+				logger.Errorf("[protocol] %s/%d run error\n%v", name, p.Version, err)
+				runErrOnce.Do(func() { runErr = err })
+			}
+		}(name, p, rw)
+	}
+
+	go demux(stream, rws)
+
+	wg.Wait()
+	return runErr
+}
+
+// demux reads wire frames from stream until it errors or EOF, routing each
+// to the channel of the protocol its code offset belongs to, then closes
+// every protocol's channel so blocked ReadMsg calls return io.EOF.
+func demux(stream io.Reader, rws map[uint64]*protoRW) {
+	closed := make(map[*protoRW]bool)
+	defer func() {
+		for _, rw := range rws {
+			if !closed[rw] {
+				closed[rw] = true
+				close(rw.in)
+			}
+		}
+	}()
+
+	for {
+		code, payload, err := readFrame(stream)
+		if err != nil {
+			return
+		}
+
+		if code == peers.FarewellCode {
+			reason, err := peers.ReadFarewellReason(payload)
+			if err != nil {
+				logger.Warnf("[protocol] got malformed farewell frame\n%v", err)
+				return
+			}
+			logger.Infof("[protocol] peer disconnecting: %s", reason)
+			return
+		}
+
+		rw, ok := rws[code]
+		if !ok {
+			logger.Warnf("[protocol] dropping frame with unknown code %d", code)
+			continue
+		}
+		if closed[rw] {
+			continue
+		}
+
+		rw.in <- Msg{
+			Code:    code - rw.proto.offset,
+			Size:    uint32(len(payload)),
+			Payload: bytes.NewReader(payload),
+		}
+	}
+}
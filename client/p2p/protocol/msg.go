@@ -0,0 +1,226 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// ErrMsgTooLarge is returned when a received message exceeds maxMsgSize.
+var ErrMsgTooLarge = errors.New("protocol: message too large")
+
+// maxMsgSize bounds how large a single framed message payload may be.
+const maxMsgSize = 16 * 1024 * 1024
+
+// Msg is a framed, demultiplexed subprotocol message, analogous to
+// go-ethereum's p2p.Msg. Code is the subprotocol-relative message code
+// (already had the protocol's code offset subtracted).
+type Msg struct {
+	Code       uint64
+	Size       uint32
+	Payload    io.Reader
+	ReceivedAt time.Time
+}
+
+// Discard reads and discards the remainder of the message payload so the
+// underlying stream can be reused for the next frame.
+func (m Msg) Discard() error {
+	_, err := io.Copy(ioutil.Discard, m.Payload)
+	return err
+}
+
+// Decode reads the SCALE-encoded payload into out, which must implement
+// `Decode([]byte) error`.
+func (m Msg) Decode(out interface {
+	Decode([]byte) error
+}) error {
+	buf := make([]byte, m.Size)
+	if _, err := io.ReadFull(m.Payload, buf); err != nil {
+		return err
+	}
+	return out.Decode(buf)
+}
+
+// MsgReader reads messages, one frame at a time.
+type MsgReader interface {
+	ReadMsg() (Msg, error)
+}
+
+// MsgWriter writes a message, consuming the whole of its payload.
+type MsgWriter interface {
+	WriteMsg(Msg) error
+}
+
+// MsgReadWriter combines reading and writing messages for a subprotocol's
+// Run function.
+type MsgReadWriter interface {
+	MsgReader
+	MsgWriter
+}
+
+// SendItems writes a message with the given code whose payload is the
+// SCALE/byte-encoding of data, which must each implement
+// `Encode() ([]byte, error)` or be a raw []byte.
+func SendItems(w MsgWriter, code uint64, data ...interface{}) error {
+	buf := new(bytes.Buffer)
+	for _, item := range data {
+		b, err := encodeItem(item)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+
+	return w.WriteMsg(Msg{
+		Code:    code,
+		Size:    uint32(buf.Len()),
+		Payload: bytes.NewReader(buf.Bytes()),
+	})
+}
+
+func encodeItem(item interface{}) ([]byte, error) {
+	switch v := item.(type) {
+	case []byte:
+		return v, nil
+	case interface {
+		Encode() ([]byte, error)
+	}:
+		return v.Encode()
+	default:
+		return nil, fmt.Errorf("protocol: cannot encode item of type %T", item)
+	}
+}
+
+// ExpectMsg reads a message from r, verifies its code matches, and decodes
+// its payload into content when non-nil.
+func ExpectMsg(r MsgReader, code uint64, content interface {
+	Decode([]byte) error
+}) error {
+	msg, err := r.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != code {
+		return fmt.Errorf("protocol: expected msg code %d, got %d", code, msg.Code)
+	}
+	if content == nil {
+		return msg.Discard()
+	}
+
+	return msg.Decode(content)
+}
+
+// MsgPipe returns two connected MsgReadWriters, in-memory, for use in
+// subprotocol unit tests. Writing on one end yields a Msg on the other.
+func MsgPipe() (*MsgPipeRW, *MsgPipeRW) {
+	c1, c2 := make(chan Msg, 16), make(chan Msg, 16)
+
+	rw1 := &MsgPipeRW{w: c1, r: c2}
+	rw2 := &MsgPipeRW{w: c2, r: c1}
+
+	return rw1, rw2
+}
+
+// MsgPipeRW is one end of an in-memory MsgReadWriter pair created by
+// MsgPipe.
+type MsgPipeRW struct {
+	w chan Msg
+	r chan Msg
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// WriteMsg buffers msg's payload (so it can be read after the writer moves
+// on) and delivers it to the pipe's other end.
+func (p *MsgPipeRW) WriteMsg(msg Msg) error {
+	buf := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, buf); err != nil {
+		return err
+	}
+	msg.Payload = bytes.NewReader(buf)
+	msg.ReceivedAt = time.Time{}
+
+	select {
+	case p.w <- msg:
+		return nil
+	case <-p.done():
+		return io.ErrClosedPipe
+	}
+}
+
+// ReadMsg blocks until a message written from the other end is available.
+func (p *MsgPipeRW) ReadMsg() (Msg, error) {
+	select {
+	case msg := <-p.r:
+		msg.ReceivedAt = time.Now()
+		return msg, nil
+	case <-p.done():
+		return Msg{}, io.ErrClosedPipe
+	}
+}
+
+// Close closes the pipe; pending and future Read/WriteMsg calls return
+// io.ErrClosedPipe.
+func (p *MsgPipeRW) Close() error {
+	p.closeOnce.Do(func() { close(p.done()) })
+	return nil
+}
+
+func (p *MsgPipeRW) done() chan struct{} {
+	if p.closed == nil {
+		p.closed = make(chan struct{})
+	}
+	return p.closed
+}
+
+// readFrame reads one wire frame (8-byte little-endian code + 4-byte
+// little-endian size + payload) from r.
+func readFrame(r io.Reader) (code uint64, payload []byte, err error) {
+	header := make([]byte, 8)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	code = binary.LittleEndian.Uint64(header)
+
+	sizeBuf := make([]byte, 4)
+	if _, err = io.ReadFull(r, sizeBuf); err != nil {
+		return 0, nil, err
+	}
+	size := binary.LittleEndian.Uint32(sizeBuf)
+	if size > maxMsgSize {
+		return 0, nil, ErrMsgTooLarge
+	}
+
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return code, payload, nil
+}
+
+// writeFrame writes one wire frame (8-byte little-endian code + 4-byte
+// little-endian size + payload) to w.
+func writeFrame(w io.Writer, code uint64, payload []byte) error {
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint64(header, code)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	sizeBuf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBuf, uint32(len(payload)))
+	if _, err := w.Write(sizeBuf); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+}
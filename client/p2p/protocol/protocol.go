@@ -0,0 +1,157 @@
+// Package protocol implements a devp2p-style Protocol/Msg subprotocol
+// multiplexer on top of a single libp2p stream, so that independent
+// subprotocols (block sync, light client, gossip, telemetry, ...) can share
+// one connection instead of each opening its own stream.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/common/scale"
+)
+
+// ErrNoMatchingProtocols is returned when two peers share no (name,version)
+// subprotocol in common.
+var ErrNoMatchingProtocols = errors.New("protocol: no matching subprotocols with remote peer")
+
+// Protocol describes a single subprotocol that can be multiplexed onto a
+// stream: its identity (Name, Version), how many message codes it
+// reserves (Length), and the function that drives it once negotiated.
+type Protocol struct {
+	// Name is the protocol's unique identifier, e.g. "dot".
+	Name string
+	// Version distinguishes compatible revisions of Name.
+	Version uint
+	// Length is the number of message codes Run expects to use; it
+	// determines the contiguous code-space reserved for this protocol
+	// once negotiated.
+	Length uint64
+	// Run is invoked once per connection after successful negotiation.
+	// It should return when the peer disconnects or a protocol error
+	// occurs.
+	Run func(peer clienttypes.InterfacePeer, rw MsgReadWriter) error
+}
+
+// cap is the wire-level capability advertisement: name + version, without
+// Length or Run (those are local only).
+type cap struct {
+	Name    string
+	Version uint
+}
+
+func (c cap) String() string {
+	return fmt.Sprintf("%s/%d", c.Name, c.Version)
+}
+
+// matchProtocols finds, for every name present in both `local` and `remote`,
+// the highest shared version, then assigns each match a contiguous code
+// offset ordered by name so both peers agree on the layout.
+func matchProtocols(local []Protocol, remote []cap) (map[string]*offsetProtocol, error) {
+	remoteByName := make(map[string][]uint)
+	for _, c := range remote {
+		remoteByName[c.Name] = append(remoteByName[c.Name], c.Version)
+	}
+
+	best := make(map[string]Protocol)
+	for _, p := range local {
+		versions, ok := remoteByName[p.Name]
+		if !ok {
+			continue
+		}
+		for _, v := range versions {
+			if v == p.Version {
+				if prev, seen := best[p.Name]; !seen || p.Version > prev.Version {
+					best[p.Name] = p
+				}
+				break
+			}
+		}
+	}
+	if len(best) == 0 {
+		return nil, ErrNoMatchingProtocols
+	}
+
+	matched := make([]Protocol, 0, len(best))
+	for _, p := range best {
+		matched = append(matched, p)
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+
+	result := make(map[string]*offsetProtocol, len(matched))
+	var offset uint64
+	for _, p := range matched {
+		result[p.Name] = &offsetProtocol{Protocol: p, offset: offset}
+		offset += p.Length
+	}
+
+	return result, nil
+}
+
+type offsetProtocol struct {
+	Protocol
+	offset uint64
+}
+
+// writeCaps writes our local protocol capabilities as a compact-len vector
+// of (name, version) pairs.
+func writeCaps(w io.Writer, protos []Protocol) error {
+	enc := scale.NewEncoder(w)
+	if err := enc.EncodeCompact(uint64(len(protos))); err != nil {
+		return err
+	}
+	for _, p := range protos {
+		if err := enc.EncodeBytes([]byte(p.Name)); err != nil {
+			return err
+		}
+		if err := enc.EncodeCompact(uint64(p.Version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCaps reads a peer's advertised protocol capabilities in the format
+// written by writeCaps.
+func readCaps(r io.Reader) ([]cap, error) {
+	dec := scale.NewDecoder(r)
+	n, err := dec.DecodeLen()
+	if err != nil {
+		return nil, err
+	}
+
+	caps := make([]cap, 0, n)
+	for i := uint64(0); i < n; i++ {
+		name, err := dec.DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		version, err := dec.DecodeCompact()
+		if err != nil {
+			return nil, err
+		}
+		caps = append(caps, cap{Name: string(name), Version: uint(version)})
+	}
+
+	return caps, nil
+}
+
+// Negotiate exchanges protocol capability lists over rw (our list is
+// written first, then the peer's is read) and returns the subset of protos
+// that both sides support, each assigned a contiguous message-code offset.
+func Negotiate(rw io.ReadWriter, protos []Protocol) (map[string]*offsetProtocol, error) {
+	if err := writeCaps(rw, protos); err != nil {
+		return nil, err
+	}
+
+	remote, err := readCaps(rw)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchProtocols(protos, remote)
+}
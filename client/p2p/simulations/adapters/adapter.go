@@ -0,0 +1,85 @@
+// Package adapters provides the NodeAdapter abstraction simulations uses
+// to run a network topology either entirely in-process (SimAdapter) or as
+// real child processes (ExecAdapter), so sync/gossip code can be exercised
+// deterministically without real libp2p transports.
+package adapters
+
+import (
+	"errors"
+
+	"github.com/c3systems/go-substrate/client/p2p/protocol"
+)
+
+// ErrNodeNotFound is returned when an adapter is asked to act on a node ID
+// it has no record of.
+var ErrNodeNotFound = errors.New("adapters: node not found")
+
+// ErrNoSuchService is returned when a NodeConfig names a service the
+// process's Services registry has no constructor for.
+var ErrNoSuchService = errors.New("adapters: no such service")
+
+// NodeConfig describes a node an adapter should create: its identity and
+// the named services (registered via RegisterService) it should run.
+type NodeConfig struct {
+	ID         string
+	PrivateKey []byte
+	Name       string
+	Services   []string
+}
+
+// Node is a running simulation node, in-process or out-of-process, capable
+// of exchanging subprotocol messages with its peers via a
+// protocol.MsgReadWriter.
+type Node interface {
+	// ID returns the node's unique identifier within the simulation.
+	ID() string
+	// Start brings the node's services up.
+	Start() error
+	// Stop tears the node's services down.
+	Stop() error
+	// ServeCodec returns a MsgReadWriter connected to the peer
+	// identified by otherID, creating the underlying transport (an
+	// in-memory pipe, or a unix socket) on first use.
+	ServeCodec(otherID string) (protocol.MsgReadWriter, error)
+}
+
+// NodeAdapter creates simulation nodes, either in-process (SimAdapter) or
+// as child processes (ExecAdapter). Simulated networks are built from a
+// single adapter so every node in a topology runs the same way.
+type NodeAdapter interface {
+	// Name identifies the adapter implementation, e.g. "sim" or "exec".
+	Name() string
+	// NewNode creates (but does not start) a node from config.
+	NewNode(config *NodeConfig) (Node, error)
+}
+
+// ServiceFunc constructs the protocols a node registered under a given
+// service name should run once started.
+type ServiceFunc func(ctx *ServiceContext) ([]protocol.Protocol, error)
+
+// ServiceContext carries the per-node state a ServiceFunc needs to build
+// its protocols.
+type ServiceContext struct {
+	Config *NodeConfig
+}
+
+// services is the process-wide registry ServiceFunc constructors register
+// themselves into; ExecAdapter children look themselves up here by name
+// after re-executing the same binary.
+var services = make(map[string]ServiceFunc)
+
+// RegisterService makes a named service available to NewNode via
+// NodeConfig.Services. It must be called from an init() in any binary that
+// wants to run as an ExecAdapter child.
+func RegisterService(name string, fn ServiceFunc) {
+	services[name] = fn
+}
+
+// getService looks up a previously-registered ServiceFunc by name.
+func getService(name string) (ServiceFunc, error) {
+	fn, ok := services[name]
+	if !ok {
+		return nil, ErrNoSuchService
+	}
+	return fn, nil
+}
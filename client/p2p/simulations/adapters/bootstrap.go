@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"net"
+	"os"
+	"strings"
+)
+
+// IsSimNode reports whether this process was re-executed by an ExecAdapter
+// to run as one simulation node's child, rather than started normally. A
+// binary that registers services via RegisterService and wants to be
+// runnable as an ExecAdapter child should check this early in main() and
+// call Serve when it's true.
+func IsSimNode() bool {
+	return os.Getenv(simAdapterEnvKey) != ""
+}
+
+// Serve blocks, accepting peer connections on the unix socket named by
+// simAdapterSockEnvKey and running this node's configured protocols (named
+// by simAdapterServicesEnvKey) against each one, the accepting-side
+// counterpart to what ExecNode.ServeCodec does for the dialing side. It
+// only returns once the listener is closed or fails to accept.
+func Serve() error {
+	id := os.Getenv(simAdapterEnvKey)
+	sockAddr := os.Getenv(simAdapterSockEnvKey)
+	services := splitServices(os.Getenv(simAdapterServicesEnvKey))
+
+	protos, err := buildProtocols(&NodeConfig{ID: id, Services: services})
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(sockAddr)
+	ln, err := net.Listen("unix", sockAddr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	peer := newStubPeer(id)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		runProtocols(peer, newWireMsgReadWriter(conn), protos)
+	}
+}
+
+func splitServices(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
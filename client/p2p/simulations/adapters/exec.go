@@ -0,0 +1,240 @@
+package adapters
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c3systems/go-substrate/client/p2p/protocol"
+	"github.com/c3systems/go-substrate/logger"
+)
+
+// simAdapterEnvKey tells a re-executed binary it should run as an
+// ExecAdapter child: build its configured services and serve them over the
+// unix socket named by simAdapterSockEnvKey. A binary that wants to be
+// runnable as an ExecAdapter child must check IsSimNode() early in main()
+// and call Serve() when it's true.
+const simAdapterEnvKey = "GOSUBSTRATE_SIMULATIONS_NODE"
+
+// simAdapterSockEnvKey names the unix socket a child listens on for peer
+// connections, one accepted conn per peer.
+const simAdapterSockEnvKey = "GOSUBSTRATE_SIMULATIONS_SOCK"
+
+// simAdapterServicesEnvKey carries the child's comma-separated
+// NodeConfig.Services, so Serve can rebuild the same protocols the parent
+// validated in NewNode.
+const simAdapterServicesEnvKey = "GOSUBSTRATE_SIMULATIONS_SERVICES"
+
+// dialSockRetries and dialSockRetryDelay bound how long ServeCodec waits
+// for another node's socket to appear: Start only guarantees the child has
+// been exec'd, not that it has reached net.Listen yet, so the first dial
+// right after Start routinely loses that race.
+const dialSockRetries = 20
+
+const dialSockRetryDelay = 50 * time.Millisecond
+
+// ExecAdapter runs every node of a simulated network as its own child
+// process (re-executing os.Args[0] with simAdapterEnvKey set), wiring each
+// one's stdio to the parent and exchanging subprotocol frames over a unix
+// socket rather than an in-process channel.
+type ExecAdapter struct {
+	// BaseDir holds one subdirectory of sockets/logs per node.
+	BaseDir string
+
+	mu    sync.Mutex
+	nodes map[string]*ExecNode
+
+	// conns caches the one connection dialed for each unordered node
+	// pair (keyed by pairKey), so that Network.Connect calling
+	// ServeCodec from both sides of a pair reuses a single socket
+	// instead of dialing twice - matching SimNode, where both ends of a
+	// MsgPipe are cached so either side's ServeCodec call is a hit.
+	conns map[string]net.Conn
+}
+
+// NewExecAdapter returns an ExecAdapter that keeps its per-node sockets
+// under baseDir.
+func NewExecAdapter(baseDir string) *ExecAdapter {
+	return &ExecAdapter{
+		BaseDir: baseDir,
+		nodes:   make(map[string]*ExecNode),
+		conns:   make(map[string]net.Conn),
+	}
+}
+
+// pairKey returns a key identifying the unordered pair (one, other), so
+// the same key is produced regardless of which side calls ServeCodec
+// first.
+func pairKey(one, other string) string {
+	if one < other {
+		return one + "-" + other
+	}
+	return other + "-" + one
+}
+
+// Name implements NodeAdapter.
+func (a *ExecAdapter) Name() string {
+	return "exec"
+}
+
+// NewNode implements NodeAdapter, preparing (but not starting) a child
+// process for config.
+func (a *ExecAdapter) NewNode(config *NodeConfig) (Node, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	protos, err := buildProtocols(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(a.BaseDir, config.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	n := &ExecNode{
+		id:       config.ID,
+		config:   config,
+		protos:   protos,
+		sockDir:  dir,
+		sockAddr: filepath.Join(dir, "node.sock"),
+		adapter:  a,
+	}
+	a.nodes[config.ID] = n
+
+	return n, nil
+}
+
+// ExecNode is a Node backed by a real child process, reached over a unix
+// socket. The socket itself is created by the child (see Serve); this
+// struct only dials out to peers and, once the connection is up, runs its
+// own copy of the node's protocols against it, mirroring what the child
+// does for the inbound side.
+type ExecNode struct {
+	id       string
+	config   *NodeConfig
+	protos   []protocol.Protocol
+	sockDir  string
+	sockAddr string
+	adapter  *ExecAdapter
+
+	cmd *exec.Cmd
+}
+
+// ID implements Node.
+func (n *ExecNode) ID() string {
+	return n.id
+}
+
+// Start implements Node: it removes any stale socket (the child, not the
+// parent, will bind it) and re-execs the current binary with
+// simAdapterEnvKey/simAdapterSockEnvKey/simAdapterServicesEnvKey set so
+// the child knows it should call Serve, which services to run, and where
+// to accept peer connections.
+func (n *ExecNode) Start() error {
+	_ = os.Remove(n.sockAddr)
+	n.adapter.forgetConns(n.id)
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Env = append(os.Environ(),
+		simAdapterEnvKey+"="+n.id,
+		simAdapterSockEnvKey+"="+n.sockAddr,
+		simAdapterServicesEnvKey+"="+strings.Join(n.config.Services, ","),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	n.cmd = cmd
+
+	return nil
+}
+
+// Stop implements Node, killing the child process.
+func (n *ExecNode) Stop() error {
+	if n.cmd != nil && n.cmd.Process != nil {
+		if err := n.cmd.Process.Kill(); err != nil {
+			logger.Warnf("[simulations] err killing exec node %s\n%v", n.id, err)
+		}
+	}
+	return nil
+}
+
+// ServeCodec returns a MsgReadWriter talking to otherID, dialing its unix
+// socket only the first time this pair is asked for. The connection is
+// cached on the adapter, not on n, so that otherID's own ServeCodec(n.id)
+// call - as Network.Connect makes from both sides - finds the same cache
+// entry instead of dialing a second, redundant connection into n's socket.
+func (n *ExecNode) ServeCodec(otherID string) (protocol.MsgReadWriter, error) {
+	a := n.adapter
+
+	a.mu.Lock()
+	key := pairKey(n.id, otherID)
+	if conn, ok := a.conns[key]; ok {
+		a.mu.Unlock()
+		return newWireMsgReadWriter(conn), nil
+	}
+	a.mu.Unlock()
+
+	otherSock := filepath.Join(filepath.Dir(n.sockDir), otherID, "node.sock")
+	conn, err := dialSockWithRetry(otherSock)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	if existing, ok := a.conns[key]; ok {
+		// otherID's ServeCodec(n.id) dialed and cached first while we
+		// were retrying; use its connection and drop ours.
+		a.mu.Unlock()
+		conn.Close()
+		return newWireMsgReadWriter(existing), nil
+	}
+	a.conns[key] = conn
+	a.mu.Unlock()
+
+	rw := newWireMsgReadWriter(conn)
+	runProtocols(newStubPeer(otherID), rw, n.protos)
+
+	return rw, nil
+}
+
+// forgetConns drops every cached pair connection involving id, so a
+// restarted node doesn't hand out a MsgReadWriter backed by a conn into its
+// previous (now-dead) process.
+func (a *ExecAdapter) forgetConns(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, conn := range a.conns {
+		if strings.HasPrefix(key, id+"-") || strings.HasSuffix(key, "-"+id) {
+			conn.Close()
+			delete(a.conns, key)
+		}
+	}
+}
+
+// dialSockWithRetry dials the unix socket at addr, retrying on a fixed
+// delay up to dialSockRetries times. The other node's process may exist
+// (exec.Start returned) well before it has called net.Listen on its
+// socket, so a single attempt right after Start routinely fails with
+// "no such file or directory" or "connection refused".
+func dialSockWithRetry(addr string) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	for i := 0; i < dialSockRetries; i++ {
+		conn, err = net.Dial("unix", addr)
+		if err == nil {
+			return conn, nil
+		}
+		time.Sleep(dialSockRetryDelay)
+	}
+	return nil, err
+}
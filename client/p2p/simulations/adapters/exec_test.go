@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDialSockWithRetrySucceedsOnceListenerExists(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "node.sock")
+
+	go func() {
+		time.Sleep(2 * dialSockRetryDelay)
+		l, err := net.Listen("unix", addr)
+		if err != nil {
+			return
+		}
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}()
+
+	conn, err := dialSockWithRetry(addr)
+	if err != nil {
+		t.Fatalf("dialSockWithRetry: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDialSockWithRetryGivesUpEventually(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "never-listens.sock")
+
+	if _, err := dialSockWithRetry(addr); err == nil {
+		t.Fatal("dialSockWithRetry succeeded dialing a socket nothing ever listens on")
+	}
+}
+
+// acceptCounter listens at addr and counts how many connections it accepts,
+// standing in for a node's real child process.
+func acceptCounter(t *testing.T, addr string) *int32 {
+	t.Helper()
+
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatalf("Listen(%s): %v", addr, err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var n int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&n, 1)
+			go func() {
+				buf := make([]byte, 4096)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						conn.Close()
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return &n
+}
+
+// TestServeCodecIsIdempotentAcrossPair reproduces how Network.Connect calls
+// ServeCodec from both sides of a pair: the second call (regardless of
+// which side makes it) must hit the adapter's shared pair cache rather
+// than dialing a second, redundant connection into the other side's
+// socket.
+func TestServeCodecIsIdempotentAcrossPair(t *testing.T) {
+	base := t.TempDir()
+	dirA := filepath.Join(base, "A")
+	dirB := filepath.Join(base, "B")
+	if err := os.MkdirAll(dirA, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dirB, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptsOnA := acceptCounter(t, filepath.Join(dirA, "node.sock"))
+	acceptsOnB := acceptCounter(t, filepath.Join(dirB, "node.sock"))
+
+	adapter := NewExecAdapter(base)
+	nodeA := &ExecNode{id: "A", sockDir: dirA, sockAddr: filepath.Join(dirA, "node.sock"), adapter: adapter}
+	nodeB := &ExecNode{id: "B", sockDir: dirB, sockAddr: filepath.Join(dirB, "node.sock"), adapter: adapter}
+	adapter.nodes["A"] = nodeA
+	adapter.nodes["B"] = nodeB
+
+	if _, err := nodeA.ServeCodec("B"); err != nil {
+		t.Fatalf("nodeA.ServeCodec(B): %v", err)
+	}
+	if _, err := nodeB.ServeCodec("A"); err != nil {
+		t.Fatalf("nodeB.ServeCodec(A): %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if got := atomic.LoadInt32(acceptsOnB); got != 1 {
+		t.Fatalf("accepts on B's socket = %d, want 1 (A dials B once)", got)
+	}
+	if got := atomic.LoadInt32(acceptsOnA); got != 0 {
+		t.Fatalf("accepts on A's socket = %d, want 0 (B's ServeCodec should reuse the cached A-B connection, not dial A)", got)
+	}
+}
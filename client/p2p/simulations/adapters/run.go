@@ -0,0 +1,161 @@
+package adapters
+
+import (
+	"io"
+	"sort"
+
+	"github.com/c3systems/go-substrate/client/p2p/protocol"
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+	"github.com/c3systems/go-substrate/logger"
+
+	libp2pnet "github.com/libp2p/go-libp2p-net"
+)
+
+// runProtocols assigns each of protos a contiguous message-code offset
+// (ordered by name, the same deterministic rule protocol.Negotiate uses)
+// and runs it against rw in its own goroutine, demultiplexing incoming
+// messages by code range. Unlike a real dot-protocol negotiation,
+// simulation nodes don't exchange capability lists over the wire: every
+// node in a topology is built from the same Services registry, so both
+// ends of a connection compute the same offsets locally without needing
+// to ask the other side first.
+func runProtocols(peer clienttypes.InterfacePeer, rw protocol.MsgReadWriter, protos []protocol.Protocol) {
+	if len(protos) == 0 {
+		return
+	}
+	if len(protos) == 1 {
+		go runProto(peer, protos[0], rw)
+		return
+	}
+
+	sorted := make([]protocol.Protocol, len(protos))
+	copy(sorted, protos)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	routes := make([]*protoRoute, len(sorted))
+	byCode := make(map[uint64]*protoRoute)
+
+	var offset uint64
+	for i, p := range sorted {
+		r := &protoRoute{proto: p, offset: offset, in: make(chan protocol.Msg, 16)}
+		routes[i] = r
+		for code := uint64(0); code < p.Length; code++ {
+			byCode[offset+code] = r
+		}
+		offset += p.Length
+	}
+
+	for _, r := range routes {
+		go runProto(peer, r.proto, &routedRW{rw: rw, offset: r.offset, in: r.in})
+	}
+
+	go demuxRW(rw, routes, byCode)
+}
+
+// protoRoute is one protocol's share of a shared MsgReadWriter: its code
+// offset and the channel its demultiplexed messages are delivered on.
+type protoRoute struct {
+	proto  protocol.Protocol
+	offset uint64
+	in     chan protocol.Msg
+}
+
+// routedRW is the MsgReadWriter a single protocol's Run function sees: it
+// adds the protocol's offset back in on writes, and reads come from the
+// channel demuxRW feeds.
+type routedRW struct {
+	rw     protocol.MsgReadWriter
+	offset uint64
+	in     chan protocol.Msg
+}
+
+func (r *routedRW) WriteMsg(msg protocol.Msg) error {
+	msg.Code += r.offset
+	return r.rw.WriteMsg(msg)
+}
+
+func (r *routedRW) ReadMsg() (protocol.Msg, error) {
+	msg, ok := <-r.in
+	if !ok {
+		return protocol.Msg{}, io.EOF
+	}
+	return msg, nil
+}
+
+// demuxRW reads messages from rw until it errors, routing each to the
+// route its code belongs to, then closes every route's channel so blocked
+// ReadMsg calls return.
+func demuxRW(rw protocol.MsgReadWriter, routes []*protoRoute, byCode map[uint64]*protoRoute) {
+	defer func() {
+		for _, r := range routes {
+			close(r.in)
+		}
+	}()
+
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return
+		}
+
+		r, ok := byCode[msg.Code]
+		if !ok {
+			logger.Warnf("[adapters] dropping message with unknown code %d", msg.Code)
+			continue
+		}
+		msg.Code -= r.offset
+		r.in <- msg
+	}
+}
+
+func runProto(peer clienttypes.InterfacePeer, p protocol.Protocol, rw protocol.MsgReadWriter) {
+	if err := p.Run(peer, rw); err != nil {
+		logger.Errorf("[adapters] %s/%d run error\n%v", p.Name, p.Version, err)
+	}
+}
+
+// stubPeer is the minimal clienttypes.InterfacePeer a simulation node
+// hands a Protocol.Run: simulated nodes have no libp2p connection of their
+// own, so it carries nothing beyond the peer's node ID.
+type stubPeer struct {
+	id string
+}
+
+func newStubPeer(id string) clienttypes.InterfacePeer {
+	return &stubPeer{id: id}
+}
+
+// GetID implements clienttypes.InterfacePeer.
+func (p *stubPeer) GetID() string {
+	return p.id
+}
+
+// Cfg implements clienttypes.InterfacePeer.
+func (p *stubPeer) Cfg() clienttypes.PeerConfig {
+	return clienttypes.PeerConfig{}
+}
+
+// IsWritable implements clienttypes.InterfacePeer; a simulated peer is
+// always considered writable once connected.
+func (p *stubPeer) IsWritable() (bool, error) {
+	return true, nil
+}
+
+// IsActive implements clienttypes.InterfacePeer.
+func (p *stubPeer) IsActive() (bool, error) {
+	return true, nil
+}
+
+// AddConnection implements clienttypes.InterfacePeer; simulated peers
+// don't track a real libp2pnet.Conn.
+func (p *stubPeer) AddConnection(conn libp2pnet.Conn, outbound bool) (libp2pnet.Conn, error) {
+	return conn, nil
+}
+
+// SetStream implements clienttypes.InterfacePeer; simulated peers have no
+// stream to record.
+func (p *stubPeer) SetStream(stream libp2pnet.Stream) {}
+
+// Disconnect implements clienttypes.InterfacePeer; simulated peers are
+// torn down by the Network/adapter, not by a farewell message.
+func (p *stubPeer) Disconnect(reason clienttypes.DiscReason) {}
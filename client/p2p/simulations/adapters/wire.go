@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+
+	"github.com/c3systems/go-substrate/client/p2p/protocol"
+)
+
+// errMsgTooLarge is returned when a received frame's declared size exceeds
+// maxMsgSize, mirroring protocol.ErrMsgTooLarge's bound on the in-process
+// demuxer - without it, a compromised or buggy child node can claim a size
+// near 2^32 in the 4-byte header and crash this process via
+// make([]byte, size) before a single payload byte is read.
+var errMsgTooLarge = errors.New("adapters: message too large")
+
+// maxMsgSize bounds how large a single framed message payload may be, same
+// limit as protocol.maxMsgSize since frames crossing this wire feed into
+// the same subprotocol Run functions.
+const maxMsgSize = 16 * 1024 * 1024
+
+// wireMsgReadWriter implements protocol.MsgReadWriter over a net.Conn (the
+// unix socket ExecNode.ServeCodec dials), using the same 8-byte-code +
+// 4-byte-size frame layout the in-process protocol package uses internally.
+type wireMsgReadWriter struct {
+	conn net.Conn
+}
+
+// newWireMsgReadWriter wraps conn as a protocol.MsgReadWriter.
+func newWireMsgReadWriter(conn net.Conn) protocol.MsgReadWriter {
+	return &wireMsgReadWriter{conn: conn}
+}
+
+// WriteMsg implements protocol.MsgWriter.
+func (w *wireMsgReadWriter) WriteMsg(msg protocol.Msg) error {
+	payload, err := ioutil.ReadAll(msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header[:8], msg.Code)
+	binary.LittleEndian.PutUint32(header[8:], uint32(len(payload)))
+
+	if _, err := w.conn.Write(header); err != nil {
+		return err
+	}
+	_, err = w.conn.Write(payload)
+	return err
+}
+
+// ReadMsg implements protocol.MsgReader.
+func (w *wireMsgReadWriter) ReadMsg() (protocol.Msg, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(w.conn, header); err != nil {
+		return protocol.Msg{}, err
+	}
+
+	code := binary.LittleEndian.Uint64(header[:8])
+	size := binary.LittleEndian.Uint32(header[8:])
+	if size > maxMsgSize {
+		return protocol.Msg{}, errMsgTooLarge
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(w.conn, payload); err != nil {
+		return protocol.Msg{}, err
+	}
+
+	return protocol.Msg{
+		Code:    code,
+		Size:    size,
+		Payload: bytes.NewReader(payload),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/c3systems/go-substrate/client/p2p/protocol"
+)
+
+func TestWireMsgReadWriterRoundTrip(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	w := newWireMsgReadWriter(server)
+	r := newWireMsgReadWriter(client)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.WriteMsg(protocol.Msg{Code: 7, Size: 5, Payload: bytes.NewReader([]byte("hello"))})
+	}()
+
+	msg, err := r.ReadMsg()
+	if err != nil {
+		t.Fatalf("ReadMsg: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("WriteMsg: %v", err)
+	}
+
+	if msg.Code != 7 {
+		t.Fatalf("Code = %d, want 7", msg.Code)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(msg.Payload)
+	if buf.String() != "hello" {
+		t.Fatalf("payload = %q, want %q", buf.String(), "hello")
+	}
+}
+
+func TestWireMsgReadWriterRejectsImplausibleSize(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	r := newWireMsgReadWriter(client)
+
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header[:8], 1)
+	binary.LittleEndian.PutUint32(header[8:], maxMsgSize+1)
+
+	go server.Write(header)
+
+	if _, err := r.ReadMsg(); err != errMsgTooLarge {
+		t.Fatalf("ReadMsg err = %v, want %v", err, errMsgTooLarge)
+	}
+}
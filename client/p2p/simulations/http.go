@@ -0,0 +1,97 @@
+package simulations
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/c3systems/go-substrate/client/p2p/simulations/adapters"
+	"github.com/c3systems/go-substrate/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// NewHTTPServer returns an *http.Server exposing net's topology over HTTP:
+// POST /nodes to create a node, POST /nodes/{id}/conn/{other} to connect
+// two nodes, and GET /events to stream every Event as SSE. Integration
+// tests and visualizers drive a Network through this API instead of
+// importing it directly.
+func NewHTTPServer(net *Network) *http.Server {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/nodes", createNodeHandler(net)).Methods("POST")
+	r.HandleFunc("/nodes/{id}/conn/{other}", connectNodesHandler(net)).Methods("POST")
+	r.HandleFunc("/events", eventsHandler(net)).Methods("GET")
+
+	return &http.Server{Handler: r}
+}
+
+func createNodeHandler(net *Network) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var config adapters.NodeConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		n, err := net.NewNode(&config)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(n.Config); err != nil {
+			logger.Errorf("[simulations] err encoding node response\n%v", err)
+		}
+	}
+}
+
+func connectNodesHandler(net *Network) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		conn, err := net.Connect(vars["id"], vars["other"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(conn); err != nil {
+			logger.Errorf("[simulations] err encoding conn response\n%v", err)
+		}
+	}
+}
+
+func eventsHandler(net *Network) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		events := net.Subscribe()
+		ctx := r.Context()
+
+		for {
+			select {
+			case ev := <-events:
+				b, err := json.Marshal(ev)
+				if err != nil {
+					logger.Errorf("[simulations] err marshaling event\n%v", err)
+					continue
+				}
+				fmt.Fprintf(w, "data: %s\n\n", b)
+				flusher.Flush()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package simulations
+
+import (
+	"context"
+	"time"
+
+	"github.com/c3systems/go-substrate/client/p2p/simulations/adapters"
+	"github.com/c3systems/go-substrate/logger"
+)
+
+// MockerStep is one scripted action a Mocker performs against a Network:
+// a node joining, a node leaving, or a connection being made.
+type MockerStep struct {
+	// After is how long to wait after the previous step before running
+	// this one.
+	After time.Duration
+	// Join, when set, is the config of a node to create and start.
+	Join *adapters.NodeConfig
+	// Leave, when set, is the ID of a node to stop.
+	Leave string
+	// Connect, when both are set, connects two already-joined nodes.
+	ConnectOne, ConnectOther string
+}
+
+// Mocker replays a scripted sequence of join/leave/connect steps against a
+// Network, for exercising topology changes deterministically in tests.
+type Mocker struct {
+	net   *Network
+	steps []MockerStep
+}
+
+// NewMocker returns a Mocker that will replay steps against net when Run.
+func NewMocker(net *Network, steps ...MockerStep) *Mocker {
+	return &Mocker{net: net, steps: steps}
+}
+
+// Run executes every step in order, honoring each one's After delay, until
+// the script completes or ctx is canceled.
+func (m *Mocker) Run(ctx context.Context) error {
+	for _, step := range m.steps {
+		if step.After > 0 {
+			select {
+			case <-time.After(step.After):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := m.runStep(step); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Mocker) runStep(step MockerStep) error {
+	switch {
+	case step.Join != nil:
+		_, err := m.net.NewNode(step.Join)
+		return err
+	case step.Leave != "":
+		n := m.net.GetNode(step.Leave)
+		if n == nil {
+			logger.Warnf("[simulations] mocker: leave step for unknown node %s", step.Leave)
+			return nil
+		}
+		return n.Stop()
+	case step.ConnectOne != "" && step.ConnectOther != "":
+		_, err := m.net.Connect(step.ConnectOne, step.ConnectOther)
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package clienttypes
+
+import (
+	"bytes"
+	"errors"
+
+	handlertypes "github.com/c3systems/go-substrate/client/p2p/handler/types"
+	"github.com/c3systems/go-substrate/common/scale"
+)
+
+// BlockRequest is the sibling of BlockResponse sent by sync.RequestBlocks:
+// it asks a peer for up to Max blocks starting at StartHash, walking toward
+// genesis when Descending is set.
+type BlockRequest struct {
+	ID         uint64
+	StartHash  []byte
+	Max        uint32
+	Descending bool
+}
+
+// Kind ...
+func (r *BlockRequest) Kind() handlertypes.FuncEnum {
+	return handlertypes.BlockRequest
+}
+
+// Encode serializes the message into a bytes array
+func (r *BlockRequest) Encode() ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("clienttypes: cannot encode nil block request")
+	}
+
+	buf := new(bytes.Buffer)
+	enc := scale.NewEncoder(buf)
+
+	if err := enc.EncodeUint64(r.ID); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeBytes(r.StartHash); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeUint32(r.Max); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeBool(r.Descending); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode deserializes a bytes array into a message
+func (r *BlockRequest) Decode(buf []byte) error {
+	if r == nil {
+		return errors.New("clienttypes: cannot decode into nil block request")
+	}
+
+	dec := scale.NewDecoder(bytes.NewReader(buf))
+
+	id, err := dec.DecodeUint64()
+	if err != nil {
+		return err
+	}
+	startHash, err := dec.DecodeBytes()
+	if err != nil {
+		return err
+	}
+	max, err := dec.DecodeUint32()
+	if err != nil {
+		return err
+	}
+	descending, err := dec.DecodeBool()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	r.StartHash = startHash
+	r.Max = max
+	r.Descending = descending
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package clienttypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlockRequestEncodeDecode(t *testing.T) {
+	tests := []*BlockRequest{
+		{ID: 1, StartHash: []byte{0xde, 0xad, 0xbe, 0xef}, Max: 128, Descending: false},
+		{ID: 2, StartHash: []byte{}, Max: 0, Descending: true},
+		{ID: 0, StartHash: nil, Max: 4294967295, Descending: true},
+	}
+
+	for i, want := range tests {
+		buf, err := want.Encode()
+		if err != nil {
+			t.Fatalf("vector %d: Encode: %v", i, err)
+		}
+
+		got := &BlockRequest{}
+		if err := got.Decode(buf); err != nil {
+			t.Fatalf("vector %d: Decode: %v", i, err)
+		}
+
+		if got.ID != want.ID {
+			t.Fatalf("vector %d: ID = %d, want %d", i, got.ID, want.ID)
+		}
+		if !bytes.Equal(got.StartHash, want.StartHash) {
+			t.Fatalf("vector %d: StartHash = %x, want %x", i, got.StartHash, want.StartHash)
+		}
+		if got.Max != want.Max {
+			t.Fatalf("vector %d: Max = %d, want %d", i, got.Max, want.Max)
+		}
+		if got.Descending != want.Descending {
+			t.Fatalf("vector %d: Descending = %v, want %v", i, got.Descending, want.Descending)
+		}
+	}
+}
@@ -1,10 +1,48 @@
 package clienttypes
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	handlertypes "github.com/c3systems/go-substrate/client/p2p/handler/types"
+	"github.com/c3systems/go-substrate/common/scale"
 )
 
-// TODO...
+// Header is the SCALE-encodable block header: parent hash, block number,
+// state root, extrinsics root and the runtime digest logs.
+type Header struct {
+	ParentHash     []byte
+	Number         uint64
+	StateRoot      []byte
+	ExtrinsicsRoot []byte
+	Digest         [][]byte
+}
+
+// BlockData is a single block returned in a BlockResponse: the block hash,
+// its header, the body (extrinsics), and the optional receipt and
+// justification.
+type BlockData struct {
+	Hash          []byte
+	Header        *Header
+	Body          [][]byte
+	Receipt       []byte
+	Justification []byte
+}
+
+// BlockResponse is the reply to a sync.RequestBlocks request: a vector of
+// blocks SCALE-encoded as compact-len + concatenated BlockData entries.
+type BlockResponse struct {
+	ID     uint64
+	Blocks []*BlockData
+
+	// raw is the payload last passed to Decode, kept so Header can
+	// re-decode just the first block's header on demand instead of
+	// requiring the rest of Blocks to already be populated.
+	raw []byte
+}
 
 // Kind ...
 func (b *BlockResponse) Kind() handlertypes.FuncEnum {
@@ -13,25 +51,423 @@ func (b *BlockResponse) Kind() handlertypes.FuncEnum {
 
 // Encode serializes the message into a bytes array
 func (b *BlockResponse) Encode() ([]byte, error) {
-	return nil, nil
+	if b == nil {
+		return nil, errors.New("clienttypes: cannot encode nil block response")
+	}
+
+	buf := new(bytes.Buffer)
+	enc := scale.NewEncoder(buf)
+
+	if err := enc.EncodeUint64(b.ID); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeCompact(uint64(len(b.Blocks))); err != nil {
+		return nil, err
+	}
+	for _, blk := range b.Blocks {
+		if err := encodeBlockData(enc, blk); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
 }
 
 // Decode deserializes a bytes array into a message
-func (b *BlockResponse) Decode(bytes []byte) error {
+func (b *BlockResponse) Decode(buf []byte) error {
+	if b == nil {
+		return errors.New("clienttypes: cannot decode into nil block response")
+	}
+
+	dec := scale.NewDecoder(bytes.NewReader(buf))
+
+	id, err := dec.DecodeUint64()
+	if err != nil {
+		return err
+	}
+	b.ID = id
+
+	n, err := dec.DecodeLen()
+	if err != nil {
+		return err
+	}
+
+	blocks := make([]*BlockData, 0, n)
+	for i := uint64(0); i < n; i++ {
+		blk, err := decodeBlockData(dec)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, blk)
+	}
+	b.Blocks = blocks
+	b.raw = buf
+
 	return nil
 }
 
+func encodeBlockData(enc *scale.Encoder, blk *BlockData) error {
+	if blk == nil {
+		return errors.New("clienttypes: cannot encode nil block data")
+	}
+
+	if err := enc.EncodeBytes(blk.Hash); err != nil {
+		return err
+	}
+	if err := encodeHeader(enc, blk.Header); err != nil {
+		return err
+	}
+
+	if err := enc.EncodeCompact(uint64(len(blk.Body))); err != nil {
+		return err
+	}
+	for _, ext := range blk.Body {
+		if err := enc.EncodeBytes(ext); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeOptionBytes(blk.Receipt); err != nil {
+		return err
+	}
+	return enc.EncodeOptionBytes(blk.Justification)
+}
+
+func decodeBlockData(dec *scale.Decoder) (*BlockData, error) {
+	hash, err := dec.DecodeBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := decodeHeader(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := dec.DecodeLen()
+	if err != nil {
+		return nil, err
+	}
+	body := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		ext, err := dec.DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, ext)
+	}
+
+	receipt, err := dec.DecodeOptionBytes()
+	if err != nil {
+		return nil, err
+	}
+	justification, err := dec.DecodeOptionBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockData{
+		Hash:          hash,
+		Header:        header,
+		Body:          body,
+		Receipt:       receipt,
+		Justification: justification,
+	}, nil
+}
+
+func encodeHeader(enc *scale.Encoder, h *Header) error {
+	if h == nil {
+		return errors.New("clienttypes: cannot encode nil header")
+	}
+
+	if err := enc.EncodeBytes(h.ParentHash); err != nil {
+		return err
+	}
+	if err := enc.EncodeCompact(h.Number); err != nil {
+		return err
+	}
+	if err := enc.EncodeBytes(h.StateRoot); err != nil {
+		return err
+	}
+	if err := enc.EncodeBytes(h.ExtrinsicsRoot); err != nil {
+		return err
+	}
+	if err := enc.EncodeCompact(uint64(len(h.Digest))); err != nil {
+		return err
+	}
+	for _, log := range h.Digest {
+		if err := enc.EncodeBytes(log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeHeader(dec *scale.Decoder) (*Header, error) {
+	parentHash, err := dec.DecodeBytes()
+	if err != nil {
+		return nil, err
+	}
+	number, err := dec.DecodeCompact()
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := dec.DecodeBytes()
+	if err != nil {
+		return nil, err
+	}
+	extrinsicsRoot, err := dec.DecodeBytes()
+	if err != nil {
+		return nil, err
+	}
+	n, err := dec.DecodeLen()
+	if err != nil {
+		return nil, err
+	}
+	digest := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		log, err := dec.DecodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		digest = append(digest, log)
+	}
+
+	return &Header{
+		ParentHash:     parentHash,
+		Number:         number,
+		StateRoot:      stateRoot,
+		ExtrinsicsRoot: extrinsicsRoot,
+		Digest:         digest,
+	}, nil
+}
+
+// jsonBlockData mirrors the polkadot-js `author_submitAndWatchExtrinsic` /
+// `chain_getBlock` response shape: hex-string hashes/bytes, decimal-string
+// numbers.
+type jsonBlockData struct {
+	Hash   string `json:"hash"`
+	Header struct {
+		ParentHash     string   `json:"parentHash"`
+		Number         string   `json:"number"`
+		StateRoot      string   `json:"stateRoot"`
+		ExtrinsicsRoot string   `json:"extrinsicsRoot"`
+		Digest         []string `json:"digest"`
+	} `json:"header"`
+	Body          []string `json:"body"`
+	Receipt       *string  `json:"receipt,omitempty"`
+	Justification *string  `json:"justification,omitempty"`
+}
+
+type jsonBlockResponse struct {
+	ID     string          `json:"id"`
+	Blocks []jsonBlockData `json:"blocks"`
+}
+
 // Marshal returns json
 func (b *BlockResponse) Marshal() ([]byte, error) {
-	return nil, nil
+	if b == nil {
+		return nil, errors.New("clienttypes: cannot marshal nil block response")
+	}
+
+	out := jsonBlockResponse{
+		ID:     fmt.Sprintf("%d", b.ID),
+		Blocks: make([]jsonBlockData, 0, len(b.Blocks)),
+	}
+
+	for _, blk := range b.Blocks {
+		jb := jsonBlockData{
+			Hash:    toHex(blk.Hash),
+			Body:    toHexSlice(blk.Body),
+			Receipt: toHexPtr(blk.Receipt),
+		}
+		jb.Justification = toHexPtr(blk.Justification)
+		if blk.Header != nil {
+			jb.Header.ParentHash = toHex(blk.Header.ParentHash)
+			jb.Header.Number = fmt.Sprintf("%d", blk.Header.Number)
+			jb.Header.StateRoot = toHex(blk.Header.StateRoot)
+			jb.Header.ExtrinsicsRoot = toHex(blk.Header.ExtrinsicsRoot)
+			jb.Header.Digest = toHexSlice(blk.Header.Digest)
+		}
+		out.Blocks = append(out.Blocks, jb)
+	}
+
+	return json.Marshal(out)
 }
 
 // Unmarshal converts json to a message
-func (b *BlockResponse) Unmarshal(bytes []byte) error {
+func (b *BlockResponse) Unmarshal(buf []byte) error {
+	if b == nil {
+		return errors.New("clienttypes: cannot unmarshal into nil block response")
+	}
+
+	var in jsonBlockResponse
+	if err := json.Unmarshal(buf, &in); err != nil {
+		return err
+	}
+
+	var id uint64
+	if _, err := fmt.Sscanf(in.ID, "%d", &id); err != nil {
+		return err
+	}
+	b.ID = id
+
+	blocks := make([]*BlockData, 0, len(in.Blocks))
+	for _, jb := range in.Blocks {
+		hash, err := fromHex(jb.Hash)
+		if err != nil {
+			return err
+		}
+		body, err := fromHexSlice(jb.Body)
+		if err != nil {
+			return err
+		}
+		receipt, err := fromHexPtr(jb.Receipt)
+		if err != nil {
+			return err
+		}
+		justification, err := fromHexPtr(jb.Justification)
+		if err != nil {
+			return err
+		}
+
+		var number uint64
+		if _, err := fmt.Sscanf(jb.Header.Number, "%d", &number); err != nil {
+			return err
+		}
+		parentHash, err := fromHex(jb.Header.ParentHash)
+		if err != nil {
+			return err
+		}
+		stateRoot, err := fromHex(jb.Header.StateRoot)
+		if err != nil {
+			return err
+		}
+		extrinsicsRoot, err := fromHex(jb.Header.ExtrinsicsRoot)
+		if err != nil {
+			return err
+		}
+		digest, err := fromHexSlice(jb.Header.Digest)
+		if err != nil {
+			return err
+		}
+
+		blocks = append(blocks, &BlockData{
+			Hash: hash,
+			Header: &Header{
+				ParentHash:     parentHash,
+				Number:         number,
+				StateRoot:      stateRoot,
+				ExtrinsicsRoot: extrinsicsRoot,
+				Digest:         digest,
+			},
+			Body:          body,
+			Receipt:       receipt,
+			Justification: justification,
+		})
+	}
+	b.Blocks = blocks
+
 	return nil
 }
 
-// Header ...
-func (b *BlockResponse) Header() *Header {
-	return nil
-}
\ No newline at end of file
+// Header returns the first block's header. If b was populated via Decode,
+// it re-decodes just the header out of the raw payload, without decoding
+// any block's body, receipt, or justification; otherwise it falls back to
+// whatever Header is already set on Blocks[0].
+func (b *BlockResponse) Header() (*Header, error) {
+	if b == nil {
+		return nil, errors.New("clienttypes: nil block response")
+	}
+
+	if b.raw != nil {
+		return DecodeHeader(b.raw)
+	}
+	if len(b.Blocks) == 0 {
+		return nil, errors.New("clienttypes: block response has no blocks")
+	}
+	return b.Blocks[0].Header, nil
+}
+
+// DecodeHeader decodes only the first block's header out of a
+// SCALE-encoded BlockResponse payload (as produced by Encode), stopping
+// before the body, receipt, or justification - and before any later
+// blocks - are reached.
+func DecodeHeader(buf []byte) (*Header, error) {
+	dec := scale.NewDecoder(bytes.NewReader(buf))
+
+	if _, err := dec.DecodeUint64(); err != nil {
+		return nil, err
+	}
+	n, err := dec.DecodeLen()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New("clienttypes: block response has no blocks")
+	}
+
+	if _, err := dec.DecodeBytes(); err != nil {
+		return nil, err
+	}
+
+	return decodeHeader(dec)
+}
+
+func toHex(b []byte) string {
+	if len(b) == 0 {
+		return "0x"
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+func toHexPtr(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := toHex(b)
+	return &s
+}
+
+func toHexSlice(bs [][]byte) []string {
+	out := make([]string, len(bs))
+	for i, b := range bs {
+		out[i] = toHex(b)
+	}
+	return out
+}
+
+func fromHex(s string) ([]byte, error) {
+	s = trimHexPrefix(s)
+	if s == "" {
+		return []byte{}, nil
+	}
+	return hex.DecodeString(s)
+}
+
+func fromHexPtr(s *string) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return fromHex(*s)
+}
+
+func fromHexSlice(ss []string) ([][]byte, error) {
+	out := make([][]byte, len(ss))
+	for i, s := range ss {
+		b, err := fromHex(s)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
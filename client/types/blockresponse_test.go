@@ -0,0 +1,183 @@
+package clienttypes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/c3systems/go-substrate/common/scale"
+)
+
+// blockResponseVectors returns a handful of hand-authored BlockData
+// fixtures that exercise the SCALE framing under test: an empty block, one
+// with digest logs and a receipt, and one spanning every optional field.
+func blockResponseVectors() []*BlockResponse {
+	return []*BlockResponse{
+		{
+			ID: 1,
+			Blocks: []*BlockData{
+				{
+					Hash: []byte{0xaa, 0xbb},
+					Header: &Header{
+						ParentHash:     []byte{0x01},
+						Number:         1,
+						StateRoot:      []byte{0x02},
+						ExtrinsicsRoot: []byte{0x03},
+						Digest:         [][]byte{{0x04}, {0x05, 0x06}},
+					},
+					Body:          [][]byte{{0x10}, {0x11, 0x12}},
+					Receipt:       []byte{0x20},
+					Justification: nil,
+				},
+			},
+		},
+		{
+			ID: 2,
+			Blocks: []*BlockData{
+				{
+					Hash:          []byte{},
+					Header:        &Header{},
+					Body:          nil,
+					Receipt:       nil,
+					Justification: nil,
+				},
+				{
+					Hash: []byte{0xff},
+					Header: &Header{
+						ParentHash:     []byte{0xaa, 0xbb, 0xcc},
+						Number:         1234567890,
+						StateRoot:      []byte{0xdd},
+						ExtrinsicsRoot: []byte{0xee},
+						Digest:         nil,
+					},
+					Body:          [][]byte{},
+					Receipt:       []byte{0x01, 0x02, 0x03},
+					Justification: []byte{0x09},
+				},
+			},
+		},
+	}
+}
+
+func TestBlockResponseEncodeDecode(t *testing.T) {
+	for i, want := range blockResponseVectors() {
+		buf, err := want.Encode()
+		if err != nil {
+			t.Fatalf("vector %d: Encode: %v", i, err)
+		}
+
+		got := &BlockResponse{}
+		if err := got.Decode(buf); err != nil {
+			t.Fatalf("vector %d: Decode: %v", i, err)
+		}
+
+		if got.ID != want.ID {
+			t.Fatalf("vector %d: ID = %d, want %d", i, got.ID, want.ID)
+		}
+		if len(got.Blocks) != len(want.Blocks) {
+			t.Fatalf("vector %d: got %d blocks, want %d", i, len(got.Blocks), len(want.Blocks))
+		}
+		for j, wb := range want.Blocks {
+			gb := got.Blocks[j]
+			if !bytes.Equal(gb.Hash, wb.Hash) {
+				t.Fatalf("vector %d block %d: Hash = %x, want %x", i, j, gb.Hash, wb.Hash)
+			}
+			if gb.Header.Number != wb.Header.Number {
+				t.Fatalf("vector %d block %d: Header.Number = %d, want %d", i, j, gb.Header.Number, wb.Header.Number)
+			}
+			if !bytes.Equal(gb.Receipt, wb.Receipt) {
+				t.Fatalf("vector %d block %d: Receipt = %x, want %x", i, j, gb.Receipt, wb.Receipt)
+			}
+			if !bytes.Equal(gb.Justification, wb.Justification) {
+				t.Fatalf("vector %d block %d: Justification = %x, want %x", i, j, gb.Justification, wb.Justification)
+			}
+		}
+	}
+}
+
+func TestBlockResponseHeaderDecodesFirstBlockOnly(t *testing.T) {
+	want := blockResponseVectors()[1]
+
+	buf, err := want.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	// Header() must work from the raw payload alone, without Decode
+	// having already populated Blocks.
+	fresh := &BlockResponse{}
+	if _, err := fresh.Header(); err == nil {
+		t.Fatal("Header() on an empty response should error")
+	}
+
+	fresh.raw = buf
+	got, err := fresh.Header()
+	if err != nil {
+		t.Fatalf("Header: %v", err)
+	}
+	if got.Number != want.Blocks[0].Header.Number {
+		t.Fatalf("Header().Number = %d, want %d", got.Number, want.Blocks[0].Header.Number)
+	}
+}
+
+func TestDecodeHeaderMatchesFullDecode(t *testing.T) {
+	for i, want := range blockResponseVectors() {
+		buf, err := want.Encode()
+		if err != nil {
+			t.Fatalf("vector %d: Encode: %v", i, err)
+		}
+
+		h, err := DecodeHeader(buf)
+		if err != nil {
+			t.Fatalf("vector %d: DecodeHeader: %v", i, err)
+		}
+		if h.Number != want.Blocks[0].Header.Number {
+			t.Fatalf("vector %d: DecodeHeader.Number = %d, want %d", i, h.Number, want.Blocks[0].Header.Number)
+		}
+		if !bytes.Equal(h.ParentHash, want.Blocks[0].Header.ParentHash) {
+			t.Fatalf("vector %d: DecodeHeader.ParentHash = %x, want %x", i, h.ParentHash, want.Blocks[0].Header.ParentHash)
+		}
+	}
+}
+
+// A peer claiming a Blocks count near 2^63 in a handful of bytes must be
+// rejected with an error instead of crashing the process via an
+// out-of-range make([]*BlockData, 0, n).
+func TestBlockResponseDecodeRejectsImplausibleBlockCount(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := scale.NewEncoder(buf)
+	if err := enc.EncodeUint64(1); err != nil {
+		t.Fatalf("EncodeUint64: %v", err)
+	}
+	if err := enc.EncodeCompact(1 << 62); err != nil {
+		t.Fatalf("EncodeCompact: %v", err)
+	}
+
+	got := &BlockResponse{}
+	if err := got.Decode(buf.Bytes()); err != scale.ErrLenTooLarge {
+		t.Fatalf("Decode = %v, want %v", err, scale.ErrLenTooLarge)
+	}
+}
+
+func TestBlockResponseMarshalUnmarshal(t *testing.T) {
+	for i, want := range blockResponseVectors() {
+		buf, err := want.Marshal()
+		if err != nil {
+			t.Fatalf("vector %d: Marshal: %v", i, err)
+		}
+
+		got := &BlockResponse{}
+		if err := got.Unmarshal(buf); err != nil {
+			t.Fatalf("vector %d: Unmarshal: %v", i, err)
+		}
+
+		if got.ID != want.ID {
+			t.Fatalf("vector %d: ID = %d, want %d", i, got.ID, want.ID)
+		}
+		for j, wb := range want.Blocks {
+			gb := got.Blocks[j]
+			if gb.Header.Number != wb.Header.Number {
+				t.Fatalf("vector %d block %d: Header.Number = %d, want %d", i, j, gb.Header.Number, wb.Header.Number)
+			}
+		}
+	}
+}
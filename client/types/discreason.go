@@ -0,0 +1,53 @@
+package clienttypes
+
+import "fmt"
+
+// DiscReason is sent in a peer's farewell message so the remote end knows
+// why the connection is being torn down, mirroring devp2p's disconnect
+// reasons.
+type DiscReason uint
+
+// Disconnect reasons.
+const (
+	DiscRequested DiscReason = iota
+	DiscNetworkError
+	DiscProtocolError
+	DiscUselessPeer
+	DiscTooManyPeers
+	DiscAlreadyConnected
+	DiscIncompatibleVersion
+	DiscInvalidIdentity
+	DiscQuitting
+	DiscUnexpectedIdentity
+	DiscSelf
+	DiscSubprotocolError
+)
+
+var discReasonStrings = [...]string{
+	DiscRequested:           "disconnect requested",
+	DiscNetworkError:        "network error",
+	DiscProtocolError:       "breach of protocol",
+	DiscUselessPeer:         "useless peer",
+	DiscTooManyPeers:        "too many peers",
+	DiscAlreadyConnected:    "already connected",
+	DiscIncompatibleVersion: "incompatible p2p protocol version",
+	DiscInvalidIdentity:     "invalid node identity",
+	DiscQuitting:            "client quitting",
+	DiscUnexpectedIdentity:  "unexpected identity",
+	DiscSelf:                "connected to self",
+	DiscSubprotocolError:    "subprotocol error",
+}
+
+// String implements fmt.Stringer.
+func (d DiscReason) String() string {
+	if int(d) < len(discReasonStrings) {
+		return discReasonStrings[d]
+	}
+	return fmt.Sprintf("unknown disconnect reason %d", d)
+}
+
+// Error implements error, so a DiscReason can be returned and logged
+// wherever an error is expected.
+func (d DiscReason) Error() string {
+	return d.String()
+}
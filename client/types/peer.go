@@ -0,0 +1,42 @@
+package clienttypes
+
+import (
+	libp2pnet "github.com/libp2p/go-libp2p-net"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+)
+
+// PeerConfig is the subset of a peer's libp2p identity the rest of the
+// client needs: its peerstore.PeerInfo (ID plus known addresses).
+type PeerConfig struct {
+	Peer peerstore.PeerInfo
+}
+
+// InterfacePeer is the behavior the p2p service depends on for a single
+// connected (or connectable) peer.
+type InterfacePeer interface {
+	// GetID returns the peer's base58 peer ID.
+	GetID() string
+	// Cfg returns the peer's known identity/addresses.
+	Cfg() PeerConfig
+	// IsWritable reports whether we currently have an open, writable
+	// connection to this peer.
+	IsWritable() (bool, error)
+	// IsActive reports whether a connection to this peer is already
+	// established.
+	IsActive() (bool, error)
+	// AddConnection registers conn as this peer's connection.
+	AddConnection(conn libp2pnet.Conn, outbound bool) (libp2pnet.Conn, error)
+	// SetStream records the stream this peer's subprotocols are
+	// multiplexed over, so Disconnect has something to send a
+	// farewell message on and close.
+	SetStream(stream libp2pnet.Stream)
+	// Disconnect sends a farewell message carrying reason to the peer
+	// and closes its stream and connection.
+	Disconnect(reason DiscReason)
+}
+
+// KnownPeer pairs a peer with whatever bookkeeping the peers service
+// tracks about it.
+type KnownPeer struct {
+	Peer InterfacePeer
+}
@@ -0,0 +1,176 @@
+// Command bootnode runs a standalone libp2p DHT/discovery node: no chain
+// sync, no RPC, just enough of the stack for other nodes to use it as a
+// bootstrap peer. It is the go-substrate analogue of devp2p's bootnode.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/c3systems/go-substrate/logger"
+
+	libp2p "github.com/libp2p/go-libp2p"
+	crypto "github.com/libp2p/go-libp2p-crypto"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	host "github.com/libp2p/go-libp2p-host"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	nodeKeyFile = flag.String("nodekey", "", "path to a hex-encoded node private key")
+	nodeKeyHex  = flag.String("nodekeyhex", "", "hex-encoded node private key")
+	genKeyFile  = flag.String("genkey", "", "generate a node private key, write it to this path, and exit")
+	listenAddr  = flag.String("addr", "/ip4/0.0.0.0/tcp/30301", "listen multiaddr")
+	natFlag     = flag.String("nat", "none", "NAT port mapping mechanism (none|upnp|pmp|extip:<IP>)")
+)
+
+func main() {
+	flag.Parse()
+
+	if *genKeyFile != "" {
+		if err := generateKeyFile(*genKeyFile); err != nil {
+			logger.Errorf("[bootnode] err generating key file\n%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	priv, err := loadOrGenerateKey()
+	if err != nil {
+		logger.Errorf("[bootnode] err loading node key\n%v", err)
+		os.Exit(1)
+	}
+
+	opts := []libp2p.Option{
+		libp2p.Identity(priv),
+		libp2p.ListenAddrStrings(*listenAddr),
+	}
+	natOpt, err := natOption(*natFlag, *listenAddr)
+	if err != nil {
+		logger.Errorf("[bootnode] err configuring nat\n%v", err)
+		os.Exit(1)
+	}
+	if natOpt != nil {
+		opts = append(opts, natOpt)
+	}
+
+	ctx := context.Background()
+	h, err := libp2p.New(ctx, opts...)
+	if err != nil {
+		logger.Errorf("[bootnode] err building host\n%v", err)
+		os.Exit(1)
+	}
+	defer h.Close()
+
+	dhtSvc, err := dht.New(ctx, h)
+	if err != nil {
+		logger.Errorf("[bootnode] err building dht\n%v", err)
+		os.Exit(1)
+	}
+	if err := dhtSvc.Bootstrap(ctx); err != nil {
+		logger.Errorf("[bootnode] err bootstrapping dht\n%v", err)
+		os.Exit(1)
+	}
+
+	printBootstrapURL(h)
+
+	select {}
+}
+
+// printBootstrapURL prints every listen address as a `/p2p/<peerid>`
+// multiaddr that other nodes can pass in their own BootstrapNodes config.
+func printBootstrapURL(h host.Host) {
+	for _, addr := range h.Addrs() {
+		full := fmt.Sprintf("%s/p2p/%s", addr, h.ID().Pretty())
+		fmt.Println(full)
+	}
+}
+
+// loadOrGenerateKey resolves the node's private key from -nodekey,
+// -nodekeyhex, or (if neither is set) generates an ephemeral one, mirroring
+// devp2p's bootnode command.
+func loadOrGenerateKey() (crypto.PrivKey, error) {
+	switch {
+	case *nodeKeyFile != "":
+		b, err := ioutil.ReadFile(*nodeKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return decodeKey(strings.TrimSpace(string(b)))
+	case *nodeKeyHex != "":
+		return decodeKey(*nodeKeyHex)
+	default:
+		logger.Warnf("[bootnode] no -nodekey/-nodekeyhex given, generating an ephemeral key; peer ID will change on restart")
+		priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+		return priv, err
+	}
+}
+
+func decodeKey(hexKey string) (crypto.PrivKey, error) {
+	b, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnmarshalPrivateKey(b)
+}
+
+// generateKeyFile creates a new node key and writes its hex encoding to
+// path, as devp2p's `bootnode -genkey` does.
+func generateKeyFile(path string) error {
+	priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	b, err := crypto.MarshalPrivateKey(priv)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, []byte(hex.EncodeToString(b)), 0600)
+}
+
+// natOption translates the -nat flag into a libp2p.Option: "none" (the
+// default) configures nothing, "upnp"/"pmp" enable automatic NAT port
+// mapping, and "extip:<IP>" announces a fixed external address on
+// whatever port listenAddr (the -addr flag) is actually listening on.
+func natOption(nat string, listenAddr string) (libp2p.Option, error) {
+	switch {
+	case nat == "" || nat == "none":
+		return nil, nil
+	case nat == "upnp" || nat == "pmp":
+		return libp2p.NATPortMap(), nil
+	case strings.HasPrefix(nat, "extip:"):
+		ip := strings.TrimPrefix(nat, "extip:")
+		port, err := listenPort(listenAddr)
+		if err != nil {
+			return nil, fmt.Errorf("bootnode: cannot derive port from -addr %q\n%v", listenAddr, err)
+		}
+		extMultiaddr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%s", ip, port))
+		if err != nil {
+			return nil, fmt.Errorf("bootnode: invalid -nat extip address %q\n%v", ip, err)
+		}
+		return libp2p.AddrsFactory(func(addrs []ma.Multiaddr) []ma.Multiaddr {
+			return append(addrs, extMultiaddr)
+		}), nil
+	default:
+		return nil, fmt.Errorf("bootnode: unrecognized -nat value %q", nat)
+	}
+}
+
+// listenPort extracts the tcp port component of addr (e.g. "30301" from
+// "/ip4/0.0.0.0/tcp/30301"), so the extip advertisement always matches
+// whatever port -addr actually configured rather than a hardcoded default.
+func listenPort(addr string) (string, error) {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return "", err
+	}
+	return maddr.ValueForProtocol(ma.P_TCP)
+}
@@ -0,0 +1,253 @@
+// Package scale implements the SCALE (Simple Concatenated Aggregate
+// Little-Endian) codec used by substrate nodes on the wire and in storage.
+package scale
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrCompactOverflow is returned when a compact-encoded integer does not fit
+// in a uint64.
+var ErrCompactOverflow = errors.New("scale: compact integer overflows uint64")
+
+// ErrLenTooLarge is returned when a compact-encoded length (a byte-vector
+// length or an element count) claims more than could plausibly still be in
+// the input, so callers never have to trust a wire-supplied length as a
+// make() size or capacity hint.
+var ErrLenTooLarge = errors.New("scale: decoded length exceeds available input")
+
+// maxDecodeLen caps a decoded length when the underlying reader can't tell
+// us how many bytes remain (readLenLimiter fallback case).
+const maxDecodeLen = 16 * 1024 * 1024
+
+// readLenLimiter is implemented by readers, such as *bytes.Reader, that
+// know how many unread bytes remain.
+type readLenLimiter interface {
+	Len() int
+}
+
+// Encoder writes SCALE-encoded values to an underlying io.Writer.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// EncodeCompact writes v using the compact-integer encoding: the low two
+// bits of the first byte select a mode (single byte, two byte, four byte, or
+// big-integer) and the remaining bits hold the value.
+func (e *Encoder) EncodeCompact(v uint64) error {
+	switch {
+	case v < 1<<6:
+		_, err := e.w.Write([]byte{byte(v << 2)})
+		return err
+	case v < 1<<14:
+		buf := make([]byte, 2)
+		binary.LittleEndian.PutUint16(buf, uint16(v<<2)|0x01)
+		_, err := e.w.Write(buf)
+		return err
+	case v < 1<<30:
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, uint32(v<<2)|0x02)
+		_, err := e.w.Write(buf)
+		return err
+	default:
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, v)
+		for len(b) > 1 && b[len(b)-1] == 0 {
+			b = b[:len(b)-1]
+		}
+		if _, err := e.w.Write([]byte{byte((len(b)-4)<<2 | 0x03)}); err != nil {
+			return err
+		}
+		_, err := e.w.Write(b)
+		return err
+	}
+}
+
+// EncodeBytes writes the compact length of b followed by b itself.
+func (e *Encoder) EncodeBytes(b []byte) error {
+	if err := e.EncodeCompact(uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := e.w.Write(b)
+	return err
+}
+
+// EncodeUint32 writes v as four fixed-width little-endian bytes.
+func (e *Encoder) EncodeUint32(v uint32) error {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, v)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// EncodeUint64 writes v as eight fixed-width little-endian bytes.
+func (e *Encoder) EncodeUint64(v uint64) error {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// EncodeBool writes v as a single 0x00/0x01 byte.
+func (e *Encoder) EncodeBool(v bool) error {
+	if v {
+		_, err := e.w.Write([]byte{0x01})
+		return err
+	}
+	_, err := e.w.Write([]byte{0x00})
+	return err
+}
+
+// EncodeOptionBytes writes an option-encoded byte slice: 0x00 if b is nil,
+// otherwise 0x01 followed by the compact-length-prefixed bytes.
+func (e *Encoder) EncodeOptionBytes(b []byte) error {
+	if b == nil {
+		_, err := e.w.Write([]byte{0x00})
+		return err
+	}
+	if _, err := e.w.Write([]byte{0x01}); err != nil {
+		return err
+	}
+	return e.EncodeBytes(b)
+}
+
+// Decoder reads SCALE-encoded values from an underlying io.Reader.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// DecodeCompact reads a compact-encoded integer.
+func (d *Decoder) DecodeCompact() (uint64, error) {
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(d.r, first); err != nil {
+		return 0, err
+	}
+
+	switch first[0] & 0x03 {
+	case 0x00:
+		return uint64(first[0] >> 2), nil
+	case 0x01:
+		rest := make([]byte, 1)
+		if _, err := io.ReadFull(d.r, rest); err != nil {
+			return 0, err
+		}
+		v := binary.LittleEndian.Uint16([]byte{first[0], rest[0]})
+		return uint64(v >> 2), nil
+	case 0x02:
+		rest := make([]byte, 3)
+		if _, err := io.ReadFull(d.r, rest); err != nil {
+			return 0, err
+		}
+		v := binary.LittleEndian.Uint32(append(first, rest...))
+		return uint64(v >> 2), nil
+	default:
+		nBytes := int(first[0]>>2) + 4
+		if nBytes > 8 {
+			return 0, ErrCompactOverflow
+		}
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(d.r, buf[:nBytes]); err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(buf), nil
+	}
+}
+
+// DecodeBytes reads a compact length followed by that many raw bytes.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	n, err := d.DecodeLen()
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DecodeLen reads a compact-encoded length - the element count of a vector
+// or the byte-length of a blob - and bounds it against however much of the
+// underlying reader is actually left (when the reader exposes that, e.g.
+// *bytes.Reader) or maxDecodeLen otherwise. Without this, a peer can send a
+// handful of bytes claiming a length near 2^63 and crash the process via
+// make([]T, n) before a single element is ever read. Callers that derive a
+// slice length or capacity from the wire must go through DecodeLen rather
+// than calling DecodeCompact directly.
+func (d *Decoder) DecodeLen() (uint64, error) {
+	n, err := d.DecodeCompact()
+	if err != nil {
+		return 0, err
+	}
+
+	max := uint64(maxDecodeLen)
+	if lr, ok := d.r.(readLenLimiter); ok {
+		if rem := uint64(lr.Len()); rem < max {
+			max = rem
+		}
+	}
+	if n > max {
+		return 0, ErrLenTooLarge
+	}
+	return n, nil
+}
+
+// DecodeUint32 reads four fixed-width little-endian bytes.
+func (d *Decoder) DecodeUint32() (uint32, error) {
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+// DecodeUint64 reads eight fixed-width little-endian bytes.
+func (d *Decoder) DecodeUint64() (uint64, error) {
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// DecodeBool reads a single 0x00/0x01 byte.
+func (d *Decoder) DecodeBool() (bool, error) {
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return false, err
+	}
+	switch buf[0] {
+	case 0x00:
+		return false, nil
+	case 0x01:
+		return true, nil
+	default:
+		return false, errors.New("scale: invalid bool byte")
+	}
+}
+
+// DecodeOptionBytes reads an option-encoded byte slice, returning nil when
+// the option is empty.
+func (d *Decoder) DecodeOptionBytes() ([]byte, error) {
+	has, err := d.DecodeBool()
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+	return d.DecodeBytes()
+}
@@ -0,0 +1,191 @@
+package scale
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Hand-picked boundary values exercising every compact-integer encoding
+// mode (single/two/four byte, and the big-integer mode's minimum widths).
+func TestEncodeDecodeCompact(t *testing.T) {
+	tests := []struct {
+		name string
+		v    uint64
+		want string
+	}{
+		{"zero", 0, "00"},
+		{"single byte max", 63, "fc"},
+		{"two byte min", 64, "0101"},
+		{"two byte max", 16383, "fdff"},
+		{"four byte min", 16384, "02000100"},
+		{"four byte max", 1073741823, "feffffff"},
+		{"big integer min", 1073741824, "0300000040"},
+		{"big integer u32 max", 4294967295, "03ffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := NewEncoder(buf).EncodeCompact(tt.v); err != nil {
+				t.Fatalf("EncodeCompact(%d): %v", tt.v, err)
+			}
+			if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+				t.Fatalf("EncodeCompact(%d) = %s, want %s", tt.v, got, tt.want)
+			}
+
+			got, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeCompact()
+			if err != nil {
+				t.Fatalf("DecodeCompact: %v", err)
+			}
+			if got != tt.v {
+				t.Fatalf("DecodeCompact round-trip = %d, want %d", got, tt.v)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeBytes(t *testing.T) {
+	tests := [][]byte{
+		nil,
+		{},
+		{0x01, 0x02, 0x03},
+		bytes.Repeat([]byte{0xab}, 100),
+	}
+
+	for _, in := range tests {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeBytes(in); err != nil {
+			t.Fatalf("EncodeBytes: %v", err)
+		}
+
+		out, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeBytes()
+		if err != nil {
+			t.Fatalf("DecodeBytes: %v", err)
+		}
+		if !bytes.Equal(out, in) && !(len(out) == 0 && len(in) == 0) {
+			t.Fatalf("DecodeBytes round-trip = %x, want %x", out, in)
+		}
+	}
+}
+
+func TestEncodeDecodeOptionBytes(t *testing.T) {
+	tests := [][]byte{nil, {}, {0x42}}
+
+	for _, in := range tests {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeOptionBytes(in); err != nil {
+			t.Fatalf("EncodeOptionBytes: %v", err)
+		}
+
+		out, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeOptionBytes()
+		if err != nil {
+			t.Fatalf("DecodeOptionBytes: %v", err)
+		}
+		if in == nil {
+			if out != nil {
+				t.Fatalf("DecodeOptionBytes(nil) = %x, want nil", out)
+			}
+			continue
+		}
+		if !bytes.Equal(out, in) {
+			t.Fatalf("DecodeOptionBytes round-trip = %x, want %x", out, in)
+		}
+	}
+}
+
+func TestEncodeDecodeUint32(t *testing.T) {
+	tests := []uint32{0, 1, 255, 65536, 4294967295}
+
+	for _, v := range tests {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeUint32(v); err != nil {
+			t.Fatalf("EncodeUint32(%d): %v", v, err)
+		}
+		got, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeUint32()
+		if err != nil {
+			t.Fatalf("DecodeUint32: %v", err)
+		}
+		if got != v {
+			t.Fatalf("DecodeUint32 round-trip = %d, want %d", got, v)
+		}
+	}
+}
+
+func TestEncodeDecodeUint64(t *testing.T) {
+	tests := []uint64{0, 1, 4294967296, 18446744073709551615}
+
+	for _, v := range tests {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeUint64(v); err != nil {
+			t.Fatalf("EncodeUint64(%d): %v", v, err)
+		}
+		got, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeUint64()
+		if err != nil {
+			t.Fatalf("DecodeUint64: %v", err)
+		}
+		if got != v {
+			t.Fatalf("DecodeUint64 round-trip = %d, want %d", got, v)
+		}
+	}
+}
+
+func TestEncodeDecodeBool(t *testing.T) {
+	for _, v := range []bool{true, false} {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeBool(v); err != nil {
+			t.Fatalf("EncodeBool(%v): %v", v, err)
+		}
+		got, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeBool()
+		if err != nil {
+			t.Fatalf("DecodeBool: %v", err)
+		}
+		if got != v {
+			t.Fatalf("DecodeBool round-trip = %v, want %v", got, v)
+		}
+	}
+}
+
+func TestDecodeCompactOverflow(t *testing.T) {
+	// A big-integer mode byte claiming more than 8 trailing bytes.
+	buf := []byte{0xff}
+	if _, err := NewDecoder(bytes.NewReader(buf)).DecodeCompact(); err != ErrCompactOverflow {
+		t.Fatalf("DecodeCompact overflow = %v, want %v", err, ErrCompactOverflow)
+	}
+}
+
+// A malicious peer can encode a compact length near 2^63 in nine bytes.
+// DecodeLen (and therefore DecodeBytes) must reject it instead of handing
+// the raw wire value to make(), which panics with "len out of range".
+func TestDecodeLenRejectsImplausibleLength(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeCompact(1 << 62); err != nil {
+		t.Fatalf("EncodeCompact: %v", err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	if _, err := dec.DecodeLen(); err != ErrLenTooLarge {
+		t.Fatalf("DecodeLen = %v, want %v", err, ErrLenTooLarge)
+	}
+
+	if _, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeBytes(); err != ErrLenTooLarge {
+		t.Fatalf("DecodeBytes = %v, want %v", err, ErrLenTooLarge)
+	}
+}
+
+// When the length is plausible given what's actually left in the reader,
+// decoding still succeeds.
+func TestDecodeLenAllowsLengthWithinRemainingInput(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeBytes(bytes.Repeat([]byte{0xcc}, 10)); err != nil {
+		t.Fatalf("EncodeBytes: %v", err)
+	}
+
+	out, err := NewDecoder(bytes.NewReader(buf.Bytes())).DecodeBytes()
+	if err != nil {
+		t.Fatalf("DecodeBytes: %v", err)
+	}
+	if len(out) != 10 {
+		t.Fatalf("DecodeBytes len = %d, want 10", len(out))
+	}
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+
+	clienttypes "github.com/c3systems/go-substrate/client/types"
+)
+
+// BlockLookup resolves a block query against whatever backing store the
+// node is using for synced blocks.
+type BlockLookup interface {
+	GetBlock(hash []byte) (*clienttypes.BlockResponse, error)
+}
+
+// ChainService exposes chain data over the gorpc-based JSON-RPC layer,
+// mirroring polkadot-js's `chain` namespace (`chain_getBlock` and
+// friends). It is registered on ServerConfig and served alongside the
+// system/state/author services in NewServer.
+type ChainService struct {
+	Blocks BlockLookup
+}
+
+// GetBlockArgs is the gorpc argument type for ChainService.GetBlock.
+type GetBlockArgs struct {
+	Hash []byte
+}
+
+// GetBlockReply is the gorpc reply type for ChainService.GetBlock: the
+// polkadot-js-shaped JSON produced by BlockResponse.Marshal.
+type GetBlockReply struct {
+	Block []byte
+}
+
+// GetBlock serves chain_getBlock: it looks up the block by hash and
+// returns it JSON-marshaled in the polkadot-js response shape, so callers
+// over RPC see the same hex-string/decimal-string encoding as
+// clienttypes.BlockResponse.Marshal produces for any other consumer.
+func (s *ChainService) GetBlock(ctx context.Context, args GetBlockArgs, reply *GetBlockReply) error {
+	block, err := s.Blocks.GetBlock(args.Hash)
+	if err != nil {
+		return err
+	}
+
+	buf, err := block.Marshal()
+	if err != nil {
+		return err
+	}
+
+	reply.Block = buf
+	return nil
+}